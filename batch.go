@@ -0,0 +1,158 @@
+package jwt
+
+import (
+	"context"
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+// BatchResult is the result of verifying one of the tokens of a batch
+type BatchResult struct {
+	Index int        // Index is the position of the token in the input
+	Raw   string     // Raw is the token string
+	Token *TokenData // Token is the parsed and validated token, nil if the token is invalid
+	Err   error      // Err is the typed error indicating why the token is invalid, nil if valid
+}
+
+// VerifyBatch parses and validates the signature and claims of the provided tokens on a bounded
+// pool of workers, see `WithWorkers`. The results are returned in the same order as the tokens.
+// If the context is canceled, the tokens not verified yet fail with an ErrCanceled error and the
+// context error is returned.
+//
+//   {ctx}       - The context to cancel the verification
+//   {tokens}    - The token strings
+//   {newTarget} - Creates the instance where the claims of each token will be deserialized to
+//   {publicKey} - The public key to use for the signature validation, or a KeyResolver to resolve
+//                 it from the header of each token. Must be safe for concurrent use.
+//   {options}   - (optional) Options to customize the parsing, e.g. `WithWorkers`
+//
+func VerifyBatch(ctx context.Context, tokens []string, newTarget func() IToken, publicKey interface{}, options ...ParseOption) ([]BatchResult, error) {
+	opts := newParseOptions(options)
+	results := make([]BatchResult, len(tokens))
+
+	var (
+		wg   sync.WaitGroup
+		next atomic.Int64
+	)
+	for w := batchWorkers(opts, len(tokens)); w > 0; w-- {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				i := int(next.Add(1) - 1)
+				if i >= len(tokens) {
+					return
+				}
+				results[i] = verifyBatchToken(ctx, i, tokens[i], newTarget, publicKey, opts)
+			}
+		}()
+	}
+	wg.Wait()
+	return results, ctx.Err()
+}
+
+// VerifyStream parses and validates the signature and claims of the tokens received from the
+// provided channel on a bounded pool of workers, see `WithWorkers`. The results are sent in the
+// same order as the tokens were received, and the returned channel is closed once the input
+// channel is closed and every result was sent, or once the context is canceled.
+//
+//   {ctx}       - The context to cancel the verification
+//   {tokens}    - The channel of token strings
+//   {newTarget} - Creates the instance where the claims of each token will be deserialized to
+//   {publicKey} - The public key to use for the signature validation, or a KeyResolver to resolve
+//                 it from the header of each token. Must be safe for concurrent use.
+//   {options}   - (optional) Options to customize the parsing, e.g. `WithWorkers`
+//
+func VerifyStream(ctx context.Context, tokens <-chan string, newTarget func() IToken, publicKey interface{}, options ...ParseOption) <-chan BatchResult {
+	opts := newParseOptions(options)
+	workers := batchWorkers(opts, -1)
+
+	type job struct {
+		index  int
+		raw    string
+		result chan BatchResult
+	}
+	var (
+		out = make(chan BatchResult, workers)
+		// pending holds the result channels in input order, which bounds the number of tokens in
+		// flight and allows the results to be sent in order
+		pending = make(chan chan BatchResult, workers)
+		jobs    = make(chan job)
+	)
+
+	for w := 0; w < workers; w++ {
+		go func() {
+			for j := range jobs {
+				j.result <- verifyBatchToken(ctx, j.index, j.raw, newTarget, publicKey, opts)
+			}
+		}()
+	}
+
+	go func() {
+		defer close(jobs)
+		defer close(pending)
+		for index := 0; ; index++ {
+			var (
+				raw string
+				ok  bool
+			)
+			select {
+			case <-ctx.Done():
+				return
+			case raw, ok = <-tokens:
+				if !ok {
+					return
+				}
+			}
+			result := make(chan BatchResult, 1)
+			select {
+			case <-ctx.Done():
+				return
+			case pending <- result:
+			}
+			jobs <- job{index: index, raw: raw, result: result}
+		}
+	}()
+
+	go func() {
+		defer close(out)
+		for result := range pending {
+			r := <-result
+			if ctx.Err() != nil {
+				// Keep draining so the workers can finish, but stop sending
+				continue
+			}
+			select {
+			case <-ctx.Done():
+			case out <- r:
+			}
+		}
+	}()
+	return out
+}
+
+func verifyBatchToken(ctx context.Context, index int, raw string, newTarget func() IToken, publicKey interface{}, opts *parseOptions) BatchResult {
+	ret := BatchResult{Index: index, Raw: raw}
+	if err := ctx.Err(); err != nil {
+		ret.Err = newErrorf(ErrCanceled, "verification canceled, %s", err.Error())
+		return ret
+	}
+	var target IToken
+	if newTarget != nil {
+		target = newTarget()
+	}
+	ret.Token, ret.Err = parseAndValidate(raw, target, publicKey, opts)
+	return ret
+}
+
+func batchWorkers(opts *parseOptions, tokens int) int {
+	workers := opts.workers
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	if tokens >= 0 && workers > tokens {
+		workers = tokens
+	}
+	return workers
+}
@@ -0,0 +1,169 @@
+package jwt
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newExtendedClaims() IToken {
+	return &ExtendedClaims{}
+}
+
+// batchTokens returns valid tokens with the index as subject, except every third one which has an
+// invalid signature
+func batchTokens(t *testing.T, n int) []string {
+	ret := make([]string, n)
+	for i := range ret {
+		claims := testClaims()
+		claims.Subject = fmt.Sprint(i)
+		token, err := Sign(claims, testSecret)
+		assert.Nil(t, err)
+		if i%3 == 2 {
+			token = token[:len(token)-4] + "AAAA"
+		}
+		ret[i] = token
+	}
+	return ret
+}
+
+func assertBatchResult(t *testing.T, i int, raw string, r BatchResult) {
+	assert.Equal(t, i, r.Index)
+	assert.Equal(t, raw, r.Raw)
+	if i%3 == 2 {
+		assert.True(t, ErrInvalidSignature.IsType(r.Err), "token %d", i)
+		assert.Nil(t, r.Token)
+		return
+	}
+	assert.Nil(t, r.Err, "token %d", i)
+	assert.Equal(t, fmt.Sprint(i), r.Token.Token.(*ExtendedClaims).Subject)
+}
+
+// assertNoGoroutineLeak fails if the number of goroutines does not return to the baseline
+func assertNoGoroutineLeak(t *testing.T, baseline int) {
+	deadline := time.Now().Add(2 * time.Second)
+	for runtime.NumGoroutine() > baseline && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	assert.LessOrEqual(t, runtime.NumGoroutine(), baseline)
+}
+
+// cancelingResolver cancels the context once it resolved the provided number of keys
+func cancelingResolver(cancel context.CancelFunc, after int64) KeyResolver {
+	var calls atomic.Int64
+	return KeyResolverFunc(func(TokenHeader) (interface{}, error) {
+		if calls.Add(1) >= after {
+			cancel()
+		}
+		return testSecret, nil
+	})
+}
+
+func TestVerifyBatch(t *testing.T) {
+	baseline := runtime.NumGoroutine()
+	tokens := batchTokens(t, 50)
+	results, err := VerifyBatch(context.Background(), tokens, newExtendedClaims, testSecret, WithWorkers(4))
+	assert.Nil(t, err)
+	assert.Len(t, results, len(tokens))
+	for i, r := range results {
+		assertBatchResult(t, i, tokens[i], r)
+	}
+
+	results, err = VerifyBatch(context.Background(), nil, newExtendedClaims, testSecret)
+	assert.Nil(t, err)
+	assert.Empty(t, results)
+	assertNoGoroutineLeak(t, baseline)
+}
+
+func TestVerifyBatchCanceled(t *testing.T) {
+	baseline := runtime.NumGoroutine()
+	tokens := batchTokens(t, 10)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// A single worker verifies the first two tokens, the context is canceled while verifying the
+	// second one
+	results, err := VerifyBatch(ctx, tokens, newExtendedClaims, cancelingResolver(cancel, 2), WithWorkers(1))
+	assert.Equal(t, context.Canceled, err)
+	assert.Len(t, results, len(tokens))
+	for i, r := range results {
+		if i < 2 {
+			assertBatchResult(t, i, tokens[i], r)
+			continue
+		}
+		assert.Equal(t, i, r.Index)
+		assert.True(t, ErrCanceled.IsType(r.Err), "token %d", i)
+		assert.Nil(t, r.Token)
+	}
+	assertNoGoroutineLeak(t, baseline)
+}
+
+func TestVerifyStream(t *testing.T) {
+	baseline := runtime.NumGoroutine()
+	tokens := batchTokens(t, 50)
+	in := make(chan string)
+	go func() {
+		defer close(in)
+		for _, token := range tokens {
+			in <- token
+		}
+	}()
+
+	i := 0
+	for r := range VerifyStream(context.Background(), in, newExtendedClaims, testSecret, WithWorkers(4)) {
+		assertBatchResult(t, i, tokens[i], r)
+		i++
+	}
+	assert.Equal(t, len(tokens), i)
+	assertNoGoroutineLeak(t, baseline)
+}
+
+func TestVerifyStreamCanceled(t *testing.T) {
+	baseline := runtime.NumGoroutine()
+	tokens := batchTokens(t, 3)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// The input channel is never closed, the output channel is closed on cancellation
+	in := make(chan string, len(tokens))
+	for _, token := range tokens {
+		in <- token
+	}
+	out := VerifyStream(ctx, in, newExtendedClaims, testSecret, WithWorkers(2))
+	for i := 0; i < len(tokens); i++ {
+		select {
+		case r := <-out:
+			assertBatchResult(t, i, tokens[i], r)
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for a result")
+		}
+	}
+	cancel()
+	select {
+	case _, ok := <-out:
+		assert.False(t, ok)
+	case <-time.After(5 * time.Second):
+		t.Fatal("the output channel was not closed")
+	}
+	assertNoGoroutineLeak(t, baseline)
+
+	// Tokens received after the context is canceled are never verified
+	ctx, cancel = context.WithCancel(context.Background())
+	defer cancel()
+	in = make(chan string)
+	out = VerifyStream(ctx, in, newExtendedClaims, testSecret, WithWorkers(2))
+	cancel()
+	select {
+	case in <- tokens[0]:
+	case <-time.After(50 * time.Millisecond):
+	}
+	for r := range out {
+		assert.True(t, ErrCanceled.IsType(r.Err))
+	}
+	assertNoGoroutineLeak(t, baseline)
+}
@@ -0,0 +1,199 @@
+package jwt
+
+import (
+	"container/list"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/subtle"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	defaultCacheMaxEntries = 10000
+	defaultCacheTTL        = 5 * time.Minute
+)
+
+// VerifiedCache is an LRU cache of successful signature verifications, so tokens presented
+// repeatedly are only verified once while they are cached. Entries are keyed by the SHA-256 hash
+// of the raw token and only hit if the same key is provided, and expire after `TTL` or when the
+// token expires, whichever comes first. The claims are still decoded and validated on every use.
+//
+// Only keys of the standard types are cached: []byte, *rsa.PublicKey, *ecdsa.PublicKey and
+// ed25519.PublicKey. Wrapped keys, such as `signing.WeakKey`, are always verified.
+type VerifiedCache struct {
+	// MaxEntries is the maximum number of cached verifications, 10000 if zero
+	MaxEntries int
+	// TTL is the maximum time a verification is cached, 5 minutes if zero
+	TTL time.Duration
+
+	mux     sync.Mutex
+	entries map[[sha256.Size]byte]*list.Element
+	lru     *list.List
+
+	hits, misses, evictions atomic.Uint64
+}
+
+// CacheStats contains the metrics of a VerifiedCache
+type CacheStats struct {
+	Hits      uint64 // Number of verifications served from the cache
+	Misses    uint64 // Number of verifications not found in the cache
+	Evictions uint64 // Number of entries removed to make room for new ones
+	Entries   int    // Number of entries currently cached
+}
+
+type verifiedEntry struct {
+	token   [sha256.Size]byte
+	key     interface{}
+	expires time.Time
+}
+
+// NewVerifiedCache creates a new VerifiedCache
+//
+//   {maxEntries} - The maximum number of cached verifications, 10000 if zero
+//   {ttl}        - (optional) The maximum time a verification is cached, 5 minutes by default
+//
+func NewVerifiedCache(maxEntries int, ttl ...time.Duration) *VerifiedCache {
+	ret := &VerifiedCache{MaxEntries: maxEntries}
+	if len(ttl) > 0 {
+		ret.TTL = ttl[0]
+	}
+	return ret
+}
+
+// Stats returns the current metrics of the cache
+func (v *VerifiedCache) Stats() CacheStats {
+	v.mux.Lock()
+	entries := len(v.entries)
+	v.mux.Unlock()
+	return CacheStats{
+		Hits:      v.hits.Load(),
+		Misses:    v.misses.Load(),
+		Evictions: v.evictions.Load(),
+		Entries:   entries,
+	}
+}
+
+// Purge removes all the cached verifications
+func (v *VerifiedCache) Purge() {
+	v.mux.Lock()
+	v.entries = nil
+	v.lru = nil
+	v.mux.Unlock()
+}
+
+// validateSignature validates the signature of the token, using the cached result if the token was
+// already verified with the same key
+func (v *VerifiedCache) validateSignature(c *TokenData, publicKey interface{}) error {
+	key, err := c.resolveKey(publicKey)
+	if err != nil {
+		return err
+	}
+	identity := cacheKeyIdentity(key)
+	if identity == nil {
+		return c.ValidateSignature(key)
+	}
+
+	sum := sha256.Sum256([]byte(c.Raw))
+	now := time.Now()
+	if v.get(sum, identity, now) {
+		v.hits.Add(1)
+		c.validated = true
+		return nil
+	}
+	v.misses.Add(1)
+
+	if err := c.ValidateSignature(key); err != nil {
+		return err
+	}
+
+	// Tokens with invalid registered claims are not cached, they are rejected by the claims checks
+	claims, err := c.registered()
+	if err != nil {
+		return nil
+	}
+	expires := now.Add(valueOrDefault(v.TTL, defaultCacheTTL))
+	if exp := claims.ExpiresAt; exp != nil {
+		if !exp.After(now) {
+			return nil
+		}
+		if exp.Before(expires) {
+			expires = exp.Time
+		}
+	}
+	v.put(&verifiedEntry{token: sum, key: identity, expires: expires})
+	return nil
+}
+
+func (v *VerifiedCache) get(sum [sha256.Size]byte, identity interface{}, now time.Time) bool {
+	v.mux.Lock()
+	defer v.mux.Unlock()
+	elem, ok := v.entries[sum]
+	if !ok {
+		return false
+	}
+	entry := elem.Value.(*verifiedEntry)
+	if !now.Before(entry.expires) {
+		v.lru.Remove(elem)
+		delete(v.entries, sum)
+		return false
+	}
+	if !cacheKeyEqual(entry.key, identity) {
+		return false
+	}
+	v.lru.MoveToFront(elem)
+	return true
+}
+
+func (v *VerifiedCache) put(entry *verifiedEntry) {
+	v.mux.Lock()
+	defer v.mux.Unlock()
+	if v.entries == nil {
+		v.entries = map[[sha256.Size]byte]*list.Element{}
+		v.lru = list.New()
+	}
+	if elem, ok := v.entries[entry.token]; ok {
+		elem.Value = entry
+		v.lru.MoveToFront(elem)
+		return
+	}
+	max := v.MaxEntries
+	if max <= 0 {
+		max = defaultCacheMaxEntries
+	}
+	for len(v.entries) >= max {
+		oldest := v.lru.Back()
+		v.lru.Remove(oldest)
+		delete(v.entries, oldest.Value.(*verifiedEntry).token)
+		v.evictions.Add(1)
+	}
+	v.entries[entry.token] = v.lru.PushFront(entry)
+}
+
+// cacheKeyIdentity returns the value that identifies the key in the cache, or nil if verifications
+// with the key should not be cached. HMAC secrets are identified by their hash so they are not
+// retained by the cache.
+func cacheKeyIdentity(key interface{}) interface{} {
+	switch k := key.(type) {
+	case []byte:
+		return sha256.Sum256(k)
+	case *rsa.PublicKey, *ecdsa.PublicKey, ed25519.PublicKey:
+		return k
+	}
+	return nil
+}
+
+func cacheKeyEqual(a, b interface{}) bool {
+	switch k := a.(type) {
+	case [sha256.Size]byte:
+		other, ok := b.([sha256.Size]byte)
+		return ok && subtle.ConstantTimeCompare(k[:], other[:]) == 1
+	case interface{ Equal(crypto.PublicKey) bool }:
+		return k.Equal(b)
+	}
+	return false
+}
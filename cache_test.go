@@ -0,0 +1,139 @@
+package jwt
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// cachedExpiration returns the expiration of the cached verification of the token
+func cachedExpiration(v *VerifiedCache, token string) (time.Time, bool) {
+	v.mux.Lock()
+	defer v.mux.Unlock()
+	elem, ok := v.entries[sha256.Sum256([]byte(token))]
+	if !ok {
+		return time.Time{}, false
+	}
+	return elem.Value.(*verifiedEntry).expires, true
+}
+
+func signSubject(t *testing.T, subject string, exp time.Duration) string {
+	claims := testClaims()
+	claims.Subject = subject
+	claims.ExpiresAt = NewNumericDate(time.Now().Add(exp).Truncate(time.Second))
+	token, err := Sign(claims, testSecret)
+	assert.Nil(t, err)
+	return token
+}
+
+func TestVerifiedCacheStats(t *testing.T) {
+	cache := NewVerifiedCache(10)
+	token := signSubject(t, "alice", time.Hour)
+
+	for i := 0; i < 3; i++ {
+		claims := &ExtendedClaims{}
+		_, err := ParseAndValidate(token, claims, testSecret, WithVerifiedCache(cache))
+		assert.Nil(t, err)
+		assert.Equal(t, "alice", claims.Subject, "the claims are decoded on every hit")
+	}
+	assert.Equal(t, CacheStats{Hits: 2, Misses: 1, Entries: 1}, cache.Stats())
+
+	// Failed verifications are not cached
+	_, err := ParseAndValidate(token[:len(token)-4]+"AAAA", &ExtendedClaims{}, testSecret, WithVerifiedCache(cache))
+	assert.True(t, ErrInvalidSignature.IsType(err))
+	assert.Equal(t, CacheStats{Hits: 2, Misses: 2, Entries: 1}, cache.Stats())
+
+	cache.Purge()
+	assert.Equal(t, 0, cache.Stats().Entries)
+}
+
+func TestVerifiedCacheExpiration(t *testing.T) {
+	cache := NewVerifiedCache(10, time.Hour)
+
+	// The lifetime of the entry is capped at the expiration of the token
+	token := signSubject(t, "alice", time.Minute)
+	claims := &ExtendedClaims{}
+	_, err := ParseAndValidate(token, claims, testSecret, WithVerifiedCache(cache))
+	assert.Nil(t, err)
+	expires, ok := cachedExpiration(cache, token)
+	assert.True(t, ok)
+	assert.True(t, expires.Equal(claims.ExpiresAt.Time))
+
+	token = signSubject(t, "bob", 2*time.Hour)
+	_, err = ParseAndValidate(token, &ExtendedClaims{}, testSecret, WithVerifiedCache(cache))
+	assert.Nil(t, err)
+	expires, _ = cachedExpiration(cache, token)
+	assert.WithinDuration(t, time.Now().Add(time.Hour), expires, time.Minute)
+
+	// Entries are not used once expired
+	cache = NewVerifiedCache(10, 10*time.Millisecond)
+	_, err = ParseAndValidate(token, &ExtendedClaims{}, testSecret, WithVerifiedCache(cache))
+	assert.Nil(t, err)
+	time.Sleep(20 * time.Millisecond)
+	_, err = ParseAndValidate(token, &ExtendedClaims{}, testSecret, WithVerifiedCache(cache))
+	assert.Nil(t, err)
+	assert.Equal(t, CacheStats{Misses: 2, Entries: 1}, cache.Stats())
+}
+
+func TestVerifiedCacheKey(t *testing.T) {
+	cache := NewVerifiedCache(10)
+	token := signSubject(t, "alice", time.Hour)
+	_, err := ParseAndValidate(token, &ExtendedClaims{}, testSecret, WithVerifiedCache(cache))
+	assert.Nil(t, err)
+
+	// A cached verification only hits with the key it was verified with
+	other := []byte("fedcba9876543210fedcba9876543210")
+	_, err = ParseAndValidate(token, &ExtendedClaims{}, other, WithVerifiedCache(cache))
+	assert.True(t, ErrInvalidSignature.IsType(err))
+	assert.Equal(t, CacheStats{Misses: 2, Entries: 1}, cache.Stats())
+}
+
+func TestVerifiedCacheEviction(t *testing.T) {
+	cache := NewVerifiedCache(2)
+	a, b, c := signSubject(t, "a", time.Hour), signSubject(t, "b", time.Hour), signSubject(t, "c", time.Hour)
+	for _, token := range []string{a, b, a, c} {
+		_, err := ParseAndValidate(token, &ExtendedClaims{}, testSecret, WithVerifiedCache(cache))
+		assert.Nil(t, err)
+	}
+	assert.Equal(t, CacheStats{Hits: 1, Misses: 3, Evictions: 1, Entries: 2}, cache.Stats())
+
+	// "b" was the least recently used
+	_, ok := cachedExpiration(cache, b)
+	assert.False(t, ok)
+	_, ok = cachedExpiration(cache, a)
+	assert.True(t, ok)
+	_, ok = cachedExpiration(cache, c)
+	assert.True(t, ok)
+}
+
+func TestVerifiedCacheConcurrency(t *testing.T) {
+	cache := NewVerifiedCache(5)
+	tokens := make([]string, 10)
+	for i := range tokens {
+		tokens[i] = signSubject(t, fmt.Sprint(i), time.Hour)
+	}
+
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				claims := &ExtendedClaims{}
+				token := (g + i) % len(tokens)
+				_, err := ParseAndValidate(tokens[token], claims, testSecret, WithVerifiedCache(cache))
+				assert.Nil(t, err)
+				assert.Equal(t, fmt.Sprint(token), claims.Subject)
+			}
+		}(g)
+	}
+	wg.Wait()
+
+	stats := cache.Stats()
+	assert.Equal(t, uint64(800), stats.Hits+stats.Misses)
+	assert.LessOrEqual(t, stats.Entries, 5)
+}
@@ -11,25 +11,29 @@ const (
 	TokenTypeAuth TokenType = "auth"
 	// TokenTypeAccess is the token type for an access token
 	TokenTypeAccess TokenType = "access"
+	// TokenTypeRefresh is the token type for a refresh token, exchanged for new access tokens
+	TokenTypeRefresh TokenType = "refresh"
+	// TokenTypeID is the token type for an OpenID Connect ID token
+	TokenTypeID TokenType = "id"
 )
 
-// TokenType indicates the type of token (auth, access)
+// TokenType indicates the type of token (auth, access, refresh, id)
 type TokenType string
 
 // ExtendedClaims defines the fields for authentication/access tokens
 type ExtendedClaims struct {
 	// Audience indicates the audience of this token
 	Audience string `json:"aud,omitempty"`
-	// ExpiresAt indicates the expiration of the token in UNIX time
-	ExpiresAt int64 `json:"exp,omitempty"`
+	// ExpiresAt indicates the expiration of the token
+	ExpiresAt *NumericDate `json:"exp,omitempty"`
 	// Id is an optional unique id for the token
 	Id string `json:"jti,omitempty"`
-	// IssuedAt at indicates when the token was issued in UNIX time
-	IssuedAt int64 `json:"iat,omitempty"`
+	// IssuedAt at indicates when the token was issued
+	IssuedAt *NumericDate `json:"iat,omitempty"`
 	// Issuer indicates who issued the token
 	Issuer string `json:"iss,omitempty"`
-	// NotBefore indicates when the token becomes valid in UNIX time (Not before)
-	NotBefore int64 `json:"nbf,omitempty"`
+	// NotBefore indicates when the token becomes valid (Not before)
+	NotBefore *NumericDate `json:"nbf,omitempty"`
 	// Subject indicates the subject of the token
 	Subject string `json:"sub,omitempty"`
 	// Type indicates the token purpose (Authenticate, Access) based on the JWT standards
@@ -49,7 +53,7 @@ func (c *ExtendedClaims) IsValid() error {
 		return newErrorf(ErrNilToken, "token is nil")
 	}
 
-	now := time.Now().UTC().Unix()
+	now := time.Now()
 
 	if err := verifyExpiresAt(now, c.ExpiresAt); err != nil {
 		return err
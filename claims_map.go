@@ -6,24 +6,24 @@ func (m MapClaims) Audience() string {
 	return getString(m, "aud")
 }
 
-func (m MapClaims) ExpiresAt() int64 {
-	return getTimeInt(m, "exp")
+func (m MapClaims) ExpiresAt() *NumericDate {
+	return getNumericDate(m, "exp")
 }
 
 func (m MapClaims) Id() string {
 	return getString(m, "jti")
 }
 
-func (m MapClaims) IssuedAt() int64 {
-	return getTimeInt(m, "iat")
+func (m MapClaims) IssuedAt() *NumericDate {
+	return getNumericDate(m, "iat")
 }
 
 func (m MapClaims) Issuer() string {
 	return getString(m, "iss")
 }
 
-func (m MapClaims) NotBefore() int64 {
-	return getTimeInt(m, "nbf")
+func (m MapClaims) NotBefore() *NumericDate {
+	return getNumericDate(m, "nbf")
 }
 
 func (m MapClaims) Subject() string {
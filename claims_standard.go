@@ -1,20 +1,20 @@
 package jwt
 
 type StandardClaims struct {
-	Aud string `json:"aud,omitempty"`
-	Exp int64  `json:"exp,omitempty"`
-	Jti string `json:"jti,omitempty"`
-	Iat int64  `json:"iat,omitempty"`
-	Iss string `json:"iss,omitempty"`
-	Nbf int64  `json:"nbf,omitempty"`
-	Sub string `json:"sub,omitempty"`
+	Aud string       `json:"aud,omitempty"`
+	Exp *NumericDate `json:"exp,omitempty"`
+	Jti string       `json:"jti,omitempty"`
+	Iat *NumericDate `json:"iat,omitempty"`
+	Iss string       `json:"iss,omitempty"`
+	Nbf *NumericDate `json:"nbf,omitempty"`
+	Sub string       `json:"sub,omitempty"`
 }
 
 func (s *StandardClaims) Audience() string {
 	return s.Aud
 }
 
-func (s *StandardClaims) ExpiresAt() int64 {
+func (s *StandardClaims) ExpiresAt() *NumericDate {
 	return s.Exp
 }
 
@@ -22,7 +22,7 @@ func (s *StandardClaims) Id() string {
 	return s.Jti
 }
 
-func (s *StandardClaims) IssuedAt() int64 {
+func (s *StandardClaims) IssuedAt() *NumericDate {
 	return s.Iat
 }
 
@@ -30,10 +30,10 @@ func (s *StandardClaims) Issuer() string {
 	return s.Iss
 }
 
-func (s *StandardClaims) NotBefore() int64 {
+func (s *StandardClaims) NotBefore() *NumericDate {
 	return s.Nbf
 }
 
 func (s *StandardClaims) Subject() string {
-	return s.Subject()
+	return s.Sub
 }
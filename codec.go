@@ -0,0 +1,78 @@
+package jwt
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"sync/atomic"
+)
+
+// Codec marshals and unmarshals the token header and claims. Implementations must be safe for
+// concurrent use, and must not retain the data passed to `Unmarshal` after returning since the
+// buffer may be reused, as with `json.Unmarshaler`.
+type Codec interface {
+	Marshal(v interface{}) ([]byte, error)
+	Unmarshal(data []byte, v interface{}) error
+}
+
+// StdCodec is the Codec backed by the standard library `encoding/json` package, used by default
+type StdCodec struct {
+	// UseNumber decodes numbers into interface{} values as json.Number instead of float64
+	UseNumber bool
+	// DisallowUnknownFields rejects objects with members that do not match a struct field
+	DisallowUnknownFields bool
+}
+
+// Marshal returns the JSON encoding of v
+func (c StdCodec) Marshal(v interface{}) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+// Unmarshal parses the JSON encoded data and stores the result in v
+func (c StdCodec) Unmarshal(data []byte, v interface{}) error {
+	if !c.UseNumber && !c.DisallowUnknownFields {
+		return json.Unmarshal(data, v)
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	if c.UseNumber {
+		dec.UseNumber()
+	}
+	if c.DisallowUnknownFields {
+		dec.DisallowUnknownFields()
+	}
+	if err := dec.Decode(v); err != nil {
+		return err
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return errors.New("invalid character after top-level value")
+	}
+	return nil
+}
+
+type codecHolder struct {
+	codec Codec
+}
+
+var defaultCodec atomic.Value
+
+func init() {
+	defaultCodec.Store(codecHolder{codec: StdCodec{}})
+}
+
+// SetCodec replaces the Codec used to marshal and unmarshal tokens when no codec is provided with
+// `WithCodec` or `WithSigningCodec`. Passing nil restores the standard library codec.
+//
+//   {codec} - The codec to use by default
+//
+func SetCodec(codec Codec) {
+	if codec == nil {
+		codec = StdCodec{}
+	}
+	defaultCodec.Store(codecHolder{codec: codec})
+}
+
+// GetCodec returns the Codec used by default
+func GetCodec() Codec {
+	return defaultCodec.Load().(codecHolder).codec
+}
@@ -0,0 +1,112 @@
+package jwt
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/json"
+	"sync/atomic"
+	"testing"
+
+	"github.com/jucardi/go-jwt/keys"
+	"github.com/stretchr/testify/assert"
+)
+
+// aliasCodec is a test codec that reads the subject from a non-standard "user" member, proving
+// every decode goes through the configured codec
+type aliasCodec struct {
+	marshals, unmarshals atomic.Int64
+}
+
+func (c *aliasCodec) Marshal(v interface{}) ([]byte, error) {
+	c.marshals.Add(1)
+	return json.Marshal(v)
+}
+
+func (c *aliasCodec) Unmarshal(data []byte, v interface{}) error {
+	c.unmarshals.Add(1)
+	var m map[string]interface{}
+	if err := json.Unmarshal(data, &m); err != nil {
+		return err
+	}
+	if user, ok := m["user"]; ok {
+		m["sub"] = user
+		delete(m, "user")
+	}
+	data, err := json.Marshal(m)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// userClaims carries the subject in the non-standard "user" member
+type userClaims struct {
+	User string `json:"user"`
+}
+
+func (c *userClaims) IsValid() error { return nil }
+
+func TestCodecPerCall(t *testing.T) {
+	codec := &aliasCodec{}
+	data := &TokenData{Token: &userClaims{User: "alice"}}
+	token, err := data.Sign(testSecret, WithSigningCodec(codec))
+	assert.Nil(t, err)
+	assert.Equal(t, int64(2), codec.marshals.Load())
+
+	claims := &ExtendedClaims{}
+	_, err = ParseAndValidate(token, claims, testSecret, WithCodec(codec))
+	assert.Nil(t, err)
+	assert.Equal(t, "alice", claims.Subject)
+
+	// The default codec does not know the alias
+	claims = &ExtendedClaims{}
+	_, err = ParseAndValidate(token, claims, testSecret)
+	assert.Nil(t, err)
+	assert.Equal(t, "", claims.Subject)
+
+	// The revocation check reads the subject through the codec
+	store := NewMemoryRevocationStore()
+	assert.Nil(t, store.RevokeSubject("alice"))
+	_, err = ParseAndValidate(token, &ExtendedClaims{}, testSecret, WithCodec(codec), WithRevocation(store))
+	assert.True(t, ErrRevoked.IsType(err))
+
+	_, err = ParseAndValidate(token, &ExtendedClaims{}, testSecret, WithRevocation(store))
+	assert.Nil(t, err)
+}
+
+func TestCodecDefault(t *testing.T) {
+	codec := &aliasCodec{}
+	SetCodec(codec)
+	defer SetCodec(nil)
+
+	token, err := Sign(&userClaims{User: "alice"}, testSecret)
+	assert.Nil(t, err)
+	claims := &ExtendedClaims{}
+	_, err = ParseAndValidate(token, claims, testSecret)
+	assert.Nil(t, err)
+	assert.Equal(t, "alice", claims.Subject)
+	assert.True(t, codec.marshals.Load() > 0)
+	assert.True(t, codec.unmarshals.Load() > 0)
+
+	SetCodec(nil)
+	assert.Equal(t, StdCodec{}, GetCodec())
+}
+
+func TestCodecHeaderJWK(t *testing.T) {
+	public, _, err := ed25519.GenerateKey(rand.Reader)
+	assert.Nil(t, err)
+	jwk, err := keys.ExportJWK(public)
+	assert.Nil(t, err)
+
+	header := TokenHeader{}
+	assert.Nil(t, header.SetJWK(jwk))
+	data, _ := json.Marshal(header)
+	parsed := TokenHeader{}
+	assert.Nil(t, json.Unmarshal(data, &parsed))
+
+	codec := &aliasCodec{}
+	ret, err := parsed.JWK(codec)
+	assert.Nil(t, err)
+	assert.Equal(t, jwk, ret)
+	assert.Equal(t, int64(1), codec.unmarshals.Load())
+}
@@ -1,6 +1,7 @@
 package encoding
 
 import (
+	"bytes"
 	"encoding/base64"
 	"strings"
 )
@@ -18,3 +19,31 @@ func DecodeSegment(seg string) ([]byte, error) {
 
 	return base64.URLEncoding.DecodeString(seg)
 }
+
+// DecodeSegmentStrict decodes a JWT segment only if it is canonical base64url: no padding, no
+// characters outside of the base64url alphabet, no line breaks and zero trailing bits, so the
+// decoded value always encodes back to the same segment
+func DecodeSegmentStrict(seg string) ([]byte, error) {
+	if i := strings.IndexAny(seg, "\r\n"); i >= 0 {
+		return nil, base64.CorruptInputError(i)
+	}
+	return base64.RawURLEncoding.Strict().DecodeString(seg)
+}
+
+// AppendDecodeSegment decodes a JWT segment like `DecodeSegment`, appending the result to dst so
+// the caller can provide a reusable buffer. Returns the extended buffer.
+func AppendDecodeSegment(dst, seg []byte) ([]byte, error) {
+	for len(seg) > 0 && seg[len(seg)-1] == '=' {
+		seg = seg[:len(seg)-1]
+	}
+	return base64.RawURLEncoding.AppendDecode(dst, seg)
+}
+
+// AppendDecodeSegmentStrict decodes a JWT segment like `DecodeSegmentStrict`, appending the result
+// to dst so the caller can provide a reusable buffer. Returns the extended buffer.
+func AppendDecodeSegmentStrict(dst, seg []byte) ([]byte, error) {
+	if i := bytes.IndexAny(seg, "\r\n"); i >= 0 {
+		return dst, base64.CorruptInputError(i)
+	}
+	return base64.RawURLEncoding.Strict().AppendDecode(dst, seg)
+}
@@ -0,0 +1,216 @@
+package jwt
+
+import (
+	"crypto/rand"
+	"errors"
+	"strings"
+
+	"github.com/jucardi/go-jwt/encoding"
+	"github.com/jucardi/go-jwt/encryption"
+)
+
+// EncryptedData contains information about a decrypted JWE token
+type EncryptedData struct {
+	Raw     string      // Contains the original token string
+	Header  TokenHeader // The JWE protected header, first segment of the token
+	Payload []byte      // The decrypted content
+}
+
+// IsNested indicates whether the decrypted content is a JWT, as declared by the "cty" header
+func (e *EncryptedData) IsNested() bool {
+	return e != nil && normalizeMediaType(e.Header.ContentType()) == normalizeMediaType(MediaTypeJWT)
+}
+
+// Encrypt encrypts the payload and returns the compact serialization of the JWE token (RFC 7516).
+// The content is encrypted with a random content encryption key, which is encrypted for the
+// recipient with the provided key.
+//
+//   {payload}   - The content to encrypt
+//   {publicKey} - The key of the recipient: an *rsa.PublicKey for RSA-OAEP, the shared secret for
+//                 AES key wrap or direct encryption
+//   {options}   - (optional) Options to customize the encrypted token, e.g. `WithKeyAlgorithm`
+//
+func Encrypt(payload []byte, publicKey interface{}, options ...EncryptOption) (string, error) {
+	if publicKey == nil {
+		return "", newError(ErrInvalidKey, "publicKey is required")
+	}
+	opts := newEncryptOptions(options)
+
+	alg := opts.algorithm
+	if alg == "" {
+		if alg = encryption.DefaultFromKey(publicKey); alg == "" {
+			return "", newErrorf(ErrEncryptAlgorithm, "failed to determine default key management algorithm from provided publicKey")
+		}
+	}
+	manager := alg.KeyManager()
+	if manager == nil {
+		return "", newErrorf(ErrEncryptAlgorithm, "key management algorithm '%s' not supported", alg)
+	}
+	encryptor := opts.encryption.Encryptor()
+	if encryptor == nil {
+		return "", newErrorf(ErrEncryptAlgorithm, "content encryption '%s' not supported", opts.encryption)
+	}
+
+	header := TokenHeader{}
+	header.SetKeyAlgorithm(alg)
+	header.SetContentEncryption(opts.encryption)
+	if opts.keyID != "" {
+		header.SetKeyID(opts.keyID)
+	}
+	if opts.contentType != "" {
+		header.SetContentType(opts.contentType)
+	}
+	hBytes, err := GetCodec().Marshal(header)
+	if err != nil {
+		return "", errors.New("failed to marshal token header, " + err.Error())
+	}
+
+	cek, encryptedKey, err := manager.EncryptKey(publicKey, encryptor.KeySize())
+	if err != nil {
+		return "", encryptionError(err)
+	}
+	// The encoded protected header is the additional authenticated data
+	protected := encoding.EncodeSegment(hBytes)
+	iv, ciphertext, tag, err := encryptor.Encrypt(cek, []byte(protected), payload)
+	if err != nil {
+		return "", encryptionError(err)
+	}
+	return strings.Join([]string{
+		protected,
+		encoding.EncodeSegment(encryptedKey),
+		encoding.EncodeSegment(iv),
+		encoding.EncodeSegment(ciphertext),
+		encoding.EncodeSegment(tag),
+	}, "."), nil
+}
+
+// EncryptNested encrypts a signed token, e.g. obtained with `Sign`, producing a nested JWT whose
+// "cty" header is "JWT" as defined in RFC 7519 section 5.2. Use `ParseAndValidateNested` to decrypt
+// and validate it.
+//
+//   {token}     - The signed token to encrypt
+//   {publicKey} - The key of the recipient, see `Encrypt`
+//   {options}   - (optional) Options to customize the encrypted token, e.g. `WithContentEncryption`
+//
+func EncryptNested(token string, publicKey interface{}, options ...EncryptOption) (string, error) {
+	return Encrypt([]byte(token), publicKey, append(options, WithContentType(MediaTypeJWT))...)
+}
+
+// Decrypt decrypts the provided JWE token and verifies the integrity of its content. Tokens using
+// compression ("zip") are rejected.
+//
+//   {tokenString} - The encrypted token string.
+//   {privateKey}  - The key to decrypt the content encryption key with, or a KeyResolver to resolve
+//                   it from the token header
+//   {options}     - (optional) Options to customize the parsing, e.g. `WithLimits`
+//
+func Decrypt(tokenString string, privateKey interface{}, options ...ParseOption) (*EncryptedData, error) {
+	return decrypt(tokenString, privateKey, newParseOptions(options))
+}
+
+// ParseAndValidateNested decrypts the provided nested token, signed then encrypted, and parses and
+// validates the signed token it contains like `ParseAndValidate`. Encrypted tokens whose "cty"
+// header is not "JWT" are rejected with ErrTokenType. The options apply to both tokens.
+//
+//   {tokenString} - The encrypted token string.
+//   {target}      - The instance where the token claims will be deserialized to.
+//   {privateKey}  - The key to decrypt the token with, or a KeyResolver to resolve it
+//   {publicKey}   - The public key to use for the signature validation, or a KeyResolver to resolve it
+//   {options}     - (optional) Options to customize the parsing, e.g. `WithAcceptedTypes`
+//
+func ParseAndValidateNested(tokenString string, target IToken, privateKey, publicKey interface{}, options ...ParseOption) (*TokenData, error) {
+	opts := newParseOptions(options)
+	data, err := decrypt(tokenString, privateKey, opts)
+	if err != nil {
+		return nil, err
+	}
+	if !data.IsNested() {
+		return nil, newErrorf(ErrTokenType, "the encrypted token does not contain a JWT, unexpected content type '%s'", data.Header.ContentType())
+	}
+	return parseAndValidate(string(data.Payload), target, publicKey, opts)
+}
+
+// decrypt splits the token, decodes its header and decrypts its content
+func decrypt(tokenString string, privateKey interface{}, opts *parseOptions) (*EncryptedData, error) {
+	if privateKey == nil {
+		return nil, newErrorf(ErrInvalidKey, "'privateKey' is required to decrypt the token")
+	}
+	if err := opts.limits.checkTokenLength(tokenString); err != nil {
+		return nil, err
+	}
+	parts := strings.SplitN(tokenString, ".", 6)
+	if len(parts) != 5 {
+		return nil, newError(ErrMalformedToken, "unexpected number of pieces")
+	}
+	if err := checkSegmentSize("header", parts[0], opts.limits.MaxHeaderSize); err != nil {
+		return nil, err
+	}
+
+	decode := encoding.DecodeSegment
+	if opts.strict {
+		decode = encoding.DecodeSegmentStrict
+	}
+	segments := make([][]byte, len(parts))
+	for i, name := range []string{"header", "encrypted key", "initialization vector", "ciphertext", "authentication tag"} {
+		var err error
+		if segments[i], err = decode(parts[i]); err != nil {
+			return nil, decodeError(name, err, opts.strict)
+		}
+	}
+
+	header := segments[0]
+	if err := opts.limits.checkJSON("header", header, false); err != nil {
+		return nil, err
+	}
+	if opts.strict {
+		if err := verifyStrictJSON("header", header); err != nil {
+			return nil, err
+		}
+	}
+	h := TokenHeader{}
+	if err := opts.codec.Unmarshal(header, &h); err != nil {
+		return nil, newErrorf(ErrUnmarshalFailed, "failed to unmarshal header, %s", err.Error())
+	}
+	if err := verifyCritical(h, opts.critical); err != nil {
+		return nil, err
+	}
+	if _, ok := h[headerZipKey]; ok {
+		return nil, newErrorf(ErrEncryptAlgorithm, "compressed tokens are not supported")
+	}
+
+	manager := h.KeyAlgorithm().KeyManager()
+	if manager == nil {
+		return nil, newErrorf(ErrEncryptAlgorithm, "key management algorithm '%s' not supported", h.KeyAlgorithm())
+	}
+	encryptor := h.ContentEncryption().Encryptor()
+	if encryptor == nil {
+		return nil, newErrorf(ErrEncryptAlgorithm, "content encryption '%s' not supported", h.ContentEncryption())
+	}
+	privateKey, err := resolveKey(h, privateKey, "decrypt the token")
+	if err != nil {
+		return nil, err
+	}
+
+	cek, err := manager.DecryptKey(segments[1], privateKey, encryptor.KeySize())
+	if errors.Is(err, encryption.ErrInvalidKey) {
+		return nil, encryptionError(err)
+	} else if err != nil {
+		// RFC 7516 section 11.5: continue with a random key, so an invalid encrypted key cannot be
+		// told apart from invalid content
+		cek = make([]byte, encryptor.KeySize())
+		if _, err := rand.Read(cek); err != nil {
+			return nil, err
+		}
+	}
+	payload, err := encryptor.Decrypt(cek, []byte(parts[0]), segments[2], segments[3], segments[4])
+	if errors.Is(err, encryption.ErrInvalidKey) {
+		return nil, encryptionError(err)
+	} else if err != nil {
+		return nil, newError(ErrDecryptionFailed, "failed to decrypt the token")
+	}
+	return &EncryptedData{
+		Raw:     tokenString,
+		Header:  h,
+		Payload: payload,
+	}, nil
+}
@@ -0,0 +1,199 @@
+package jwt
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"strings"
+	"testing"
+
+	"github.com/jucardi/go-jwt/encoding"
+	"github.com/jucardi/go-jwt/encryption"
+	"github.com/jucardi/go-jwt/keys"
+	"github.com/stretchr/testify/assert"
+)
+
+// nestedKeys returns the ES256 signing key and the RSA encryption key of the nested tokens
+func nestedKeys(t *testing.T) (*ecdsa.PrivateKey, *rsa.PrivateKey) {
+	signKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.Nil(t, err)
+	encKey, err := keys.LoadPrivateKey("test_assets/rsa.priv.pkcs1")
+	assert.Nil(t, err)
+	return signKey, encKey.(*rsa.PrivateKey)
+}
+
+func TestNestedToken(t *testing.T) {
+	signKey, encKey := nestedKeys(t)
+	signed, err := Sign(testClaims(), signKey)
+	assert.Nil(t, err)
+
+	for _, alg := range []encryption.KeyAlgorithm{"", encryption.AlgorithmRSAOAEP, encryption.AlgorithmRSAOAEP256} {
+		for _, enc := range []encryption.ContentEncryption{encryption.EncryptionA128GCM, encryption.EncryptionA256GCM, encryption.EncryptionA128CBCHS256, encryption.EncryptionA256CBCHS512} {
+			token, err := EncryptNested(signed, &encKey.PublicKey, WithKeyAlgorithm(alg), WithContentEncryption(enc))
+			assert.Nil(t, err, alg, enc)
+			assert.Len(t, strings.Split(token, "."), 5)
+
+			claims := &ExtendedClaims{}
+			data, err := ParseAndValidateNested(token, claims, encKey, &signKey.PublicKey)
+			assert.Nil(t, err, alg, enc)
+			assert.Equal(t, signed, data.Raw)
+			assert.Equal(t, "alice", claims.Subject)
+		}
+	}
+
+	// The default algorithms and the headers of the encrypted token
+	token, err := EncryptNested(signed, &encKey.PublicKey, WithEncryptionKeyID("enc-1"), WithContentType("text/plain"))
+	assert.Nil(t, err)
+	data, err := Decrypt(token, encKey)
+	assert.Nil(t, err)
+	assert.True(t, data.IsNested())
+	assert.Equal(t, signed, string(data.Payload))
+	assert.Equal(t, encryption.AlgorithmRSAOAEP256, data.Header.KeyAlgorithm())
+	assert.Equal(t, encryption.EncryptionA256GCM, data.Header.ContentEncryption())
+	assert.Equal(t, MediaTypeJWT, data.Header.ContentType())
+	assert.Equal(t, "enc-1", data.Header.KeyID())
+}
+
+func TestNestedTokenSharedKeys(t *testing.T) {
+	signed, err := Sign(testClaims(), testSecret)
+	assert.Nil(t, err)
+
+	for alg, key := range map[encryption.KeyAlgorithm][]byte{
+		encryption.AlgorithmA128KW: testSecret[:16],
+		encryption.AlgorithmA192KW: testSecret[:24],
+		encryption.AlgorithmA256KW: testSecret,
+		encryption.AlgorithmDirect: testSecret,
+	} {
+		token, err := EncryptNested(signed, key, WithKeyAlgorithm(alg), WithContentEncryption(encryption.EncryptionA128CBCHS256))
+		assert.Nil(t, err, alg)
+		_, err = ParseAndValidateNested(token, &ExtendedClaims{}, key, testSecret)
+		assert.Nil(t, err, alg)
+	}
+
+	// Direct encryption is never the default
+	token, err := EncryptNested(signed, testSecret)
+	assert.Nil(t, err)
+	data, err := Decrypt(token, testSecret)
+	assert.Nil(t, err)
+	assert.Equal(t, encryption.AlgorithmA256KW, data.Header.KeyAlgorithm())
+}
+
+func TestNestedTokenResolvers(t *testing.T) {
+	signKey, encKey := nestedKeys(t)
+	data := &TokenData{Header: TokenHeader{}, Token: testClaims()}
+	data.Header.SetKeyID("sig-1")
+	signed, err := data.Sign(signKey, WithType(MediaTypeAccessToken))
+	assert.Nil(t, err)
+	token, err := EncryptNested(signed, &encKey.PublicKey, WithEncryptionKeyID("enc-1"))
+	assert.Nil(t, err)
+
+	resolver := func(expected string, key interface{}) KeyResolver {
+		return KeyResolverFunc(func(header TokenHeader) (interface{}, error) {
+			if header.KeyID() != expected {
+				return nil, nil
+			}
+			return key, nil
+		})
+	}
+	_, err = ParseAndValidateNested(token, &ExtendedClaims{}, resolver("enc-1", encKey), resolver("sig-1", &signKey.PublicKey), WithAcceptedTypes(MediaTypeAccessToken))
+	assert.Nil(t, err)
+
+	// The options apply to the nested token
+	_, err = ParseAndValidateNested(token, &ExtendedClaims{}, resolver("enc-1", encKey), resolver("sig-1", &signKey.PublicKey))
+	assert.True(t, ErrTokenType.IsType(err))
+
+	_, err = ParseAndValidateNested(token, &ExtendedClaims{}, resolver("enc-2", encKey), &signKey.PublicKey, WithAcceptedTypes(MediaTypeAccessToken))
+	assert.True(t, ErrKeyResolution.IsType(err))
+	assert.Contains(t, err.Error(), "decrypt the token")
+}
+
+func TestNestedTokenInvalid(t *testing.T) {
+	signKey, encKey := nestedKeys(t)
+	signed, err := Sign(testClaims(), signKey)
+	assert.Nil(t, err)
+	token, err := EncryptNested(signed, &encKey.PublicKey)
+	assert.Nil(t, err)
+
+	// Wrong keys
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.Nil(t, err)
+	_, err = ParseAndValidateNested(token, &ExtendedClaims{}, otherKey, &signKey.PublicKey)
+	assert.True(t, ErrDecryptionFailed.IsType(err))
+	_, err = ParseAndValidateNested(token, &ExtendedClaims{}, encKey, &otherKey.PublicKey)
+	assert.True(t, ErrInvalidKey.IsType(err))
+	_, err = ParseAndValidateNested(token, &ExtendedClaims{}, testSecret, &signKey.PublicKey)
+	assert.True(t, ErrInvalidKey.IsType(err))
+	_, err = ParseAndValidateNested(token, &ExtendedClaims{}, nil, &signKey.PublicKey)
+	assert.True(t, ErrInvalidKey.IsType(err))
+
+	// Tampering with the encrypted key, IV, ciphertext or tag fails the decryption
+	parts := strings.Split(token, ".")
+	for i := 1; i < len(parts); i++ {
+		tampered := append([]string{}, parts...)
+		raw, err := encoding.DecodeSegment(parts[i])
+		assert.Nil(t, err)
+		raw[0] ^= 1
+		tampered[i] = encoding.EncodeSegment(raw)
+		_, err = ParseAndValidateNested(strings.Join(tampered, "."), &ExtendedClaims{}, encKey, &signKey.PublicKey)
+		assert.True(t, ErrDecryptionFailed.IsType(err), i)
+	}
+
+	// Tampering with the header fails the authentication of the content
+	header := encoding.EncodeSegment([]byte(`{"alg":"RSA-OAEP-256","enc":"A256GCM","cty":"JWT","kid":"x"}`))
+	_, err = ParseAndValidateNested(header+token[strings.IndexByte(token, '.'):], &ExtendedClaims{}, encKey, &signKey.PublicKey)
+	assert.True(t, ErrDecryptionFailed.IsType(err))
+
+	// Encrypted content that is not a JWT
+	token, err = Encrypt([]byte(signed), &encKey.PublicKey)
+	assert.Nil(t, err)
+	_, err = ParseAndValidateNested(token, &ExtendedClaims{}, encKey, &signKey.PublicKey)
+	assert.True(t, ErrTokenType.IsType(err))
+
+	// A signed token is not an encrypted token
+	_, err = ParseAndValidateNested(signed, &ExtendedClaims{}, encKey, &signKey.PublicKey)
+	assert.True(t, ErrMalformedToken.IsType(err))
+}
+
+func TestDecryptHeader(t *testing.T) {
+	_, encKey := nestedKeys(t)
+	for header, errType := range map[string]ErrorType{
+		`{"alg":"RSA1_5","enc":"A256GCM"}`:                                 ErrEncryptAlgorithm,
+		`{"alg":"RSA-OAEP-256","enc":"A128CTR"}`:                           ErrEncryptAlgorithm,
+		`{"alg":"RSA-OAEP-256","enc":"A256GCM","zip":"DEF"}`:               ErrEncryptAlgorithm,
+		`{"alg":"RSA-OAEP-256","enc":"A256GCM","crit":["enc"]}`:            ErrCriticalHeader,
+		`{"alg":"RSA-OAEP-256","enc":"A256GCM","crit":["exp"],"exp":1}`:    ErrCriticalHeader,
+		`{"alg":"RSA-OAEP-256","enc":"A256GCM","x":[[[[[[[[[[[[[[[[[[[[[[`: ErrLimitExceeded,
+		`{"alg":"RSA-OAEP-256"`:                                            ErrUnmarshalFailed,
+	} {
+		token := encoding.EncodeSegment([]byte(header)) + ".e30.e30.e30.e30"
+		_, err := Decrypt(token, encKey, WithLimits(Limits{MaxJSONDepth: 16}))
+		assert.True(t, errType.IsType(err), header)
+	}
+
+	for _, token := range []string{"", "e30.e30.e30.e30", "e30.e30.e30.e30.e30.e30", "!.e30.e30.e30.e30"} {
+		_, err := Decrypt(token, encKey)
+		assert.True(t, ErrMalformedToken.IsType(err), token)
+	}
+
+	token, err := Encrypt([]byte("payload"), &encKey.PublicKey)
+	assert.Nil(t, err)
+	_, err = Decrypt(token, encKey, WithLimits(Limits{MaxTokenLength: len(token) - 1}))
+	assert.True(t, ErrLimitExceeded.IsType(err))
+}
+
+func TestEncryptInvalid(t *testing.T) {
+	_, encKey := nestedKeys(t)
+	_, err := Encrypt([]byte("payload"), nil)
+	assert.True(t, ErrInvalidKey.IsType(err))
+	_, err = Encrypt([]byte("payload"), "secret")
+	assert.True(t, ErrEncryptAlgorithm.IsType(err))
+	_, err = Encrypt([]byte("payload"), &encKey.PublicKey, WithKeyAlgorithm("RSA1_5"))
+	assert.True(t, ErrEncryptAlgorithm.IsType(err))
+	_, err = Encrypt([]byte("payload"), &encKey.PublicKey, WithContentEncryption("A128CTR"))
+	assert.True(t, ErrEncryptAlgorithm.IsType(err))
+	_, err = Encrypt([]byte("payload"), testSecret, WithKeyAlgorithm(encryption.AlgorithmA128KW))
+	assert.True(t, ErrInvalidKey.IsType(err))
+	_, err = Encrypt([]byte("payload"), testSecret[:16], WithKeyAlgorithm(encryption.AlgorithmDirect))
+	assert.True(t, ErrInvalidKey.IsType(err))
+}
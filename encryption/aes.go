@@ -0,0 +1,154 @@
+package encryption
+
+import (
+	"crypto/aes"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/binary"
+	"errors"
+)
+
+// keyWrapIV is the default initial value of the AES key wrap defined in RFC 3394 section 2.2.3.1
+var keyWrapIV = []byte{0xa6, 0xa6, 0xa6, 0xa6, 0xa6, 0xa6, 0xa6, 0xa6}
+
+type aesKeyManager struct {
+	alg  string
+	size int
+}
+
+func (a *aesKeyManager) Algorithm() string {
+	return a.alg
+}
+
+func (a *aesKeyManager) EncryptKey(key interface{}, cekSize int) ([]byte, []byte, error) {
+	kek, err := a.checkKey(key)
+	if err != nil {
+		return nil, nil, err
+	}
+	cek := make([]byte, cekSize)
+	if _, err := rand.Read(cek); err != nil {
+		return nil, nil, err
+	}
+	encryptedKey, err := wrapKey(kek, cek)
+	if err != nil {
+		return nil, nil, err
+	}
+	return cek, encryptedKey, nil
+}
+
+func (a *aesKeyManager) DecryptKey(encryptedKey []byte, key interface{}, cekSize int) ([]byte, error) {
+	kek, err := a.checkKey(key)
+	if err != nil {
+		return nil, err
+	}
+	cek, err := unwrapKey(kek, encryptedKey)
+	if err != nil || len(cek) != cekSize {
+		return nil, ErrDecryption
+	}
+	return cek, nil
+}
+
+func (a *aesKeyManager) checkKey(key interface{}) ([]byte, error) {
+	kek, ok := key.([]byte)
+	if !ok {
+		return nil, invalidKey(a.alg, "expected []byte")
+	}
+	if len(kek) != a.size {
+		return nil, invalidKey(a.alg, "%s requires a key of %d bytes, got %d", a.alg, a.size, len(kek))
+	}
+	return kek, nil
+}
+
+// wrapKey wraps the key with the key encryption key as defined in RFC 3394 section 2.2.1
+func wrapKey(kek, key []byte) ([]byte, error) {
+	if len(key) < 16 || len(key)%8 != 0 {
+		return nil, errors.New("the key to wrap must be a multiple of 8 bytes and at least 16 bytes long")
+	}
+	block, err := aes.NewCipher(kek)
+	if err != nil {
+		return nil, err
+	}
+
+	n := len(key) / 8
+	ret := make([]byte, len(key)+8)
+	copy(ret, keyWrapIV)
+	copy(ret[8:], key)
+
+	var b [16]byte
+	for j := 0; j < 6; j++ {
+		for i := 1; i <= n; i++ {
+			copy(b[:8], ret[:8])
+			copy(b[8:], ret[i*8:i*8+8])
+			block.Encrypt(b[:], b[:])
+			t := uint64(n*j + i)
+			binary.BigEndian.PutUint64(ret[:8], binary.BigEndian.Uint64(b[:8])^t)
+			copy(ret[i*8:], b[8:])
+		}
+	}
+	return ret, nil
+}
+
+// unwrapKey unwraps the key with the key encryption key as defined in RFC 3394 section 2.2.2 and
+// verifies its integrity
+func unwrapKey(kek, wrapped []byte) ([]byte, error) {
+	if len(wrapped) < 24 || len(wrapped)%8 != 0 {
+		return nil, errors.New("the wrapped key must be a multiple of 8 bytes and at least 24 bytes long")
+	}
+	block, err := aes.NewCipher(kek)
+	if err != nil {
+		return nil, err
+	}
+
+	n := len(wrapped)/8 - 1
+	ret := make([]byte, len(wrapped))
+	copy(ret, wrapped)
+
+	var b [16]byte
+	for j := 5; j >= 0; j-- {
+		for i := n; i >= 1; i-- {
+			t := uint64(n*j + i)
+			binary.BigEndian.PutUint64(b[:8], binary.BigEndian.Uint64(ret[:8])^t)
+			copy(b[8:], ret[i*8:i*8+8])
+			block.Decrypt(b[:], b[:])
+			copy(ret[:8], b[:8])
+			copy(ret[i*8:], b[8:])
+		}
+	}
+	if subtle.ConstantTimeCompare(ret[:8], keyWrapIV) != 1 {
+		return nil, errors.New("the wrapped key failed the integrity check")
+	}
+	return ret[8:], nil
+}
+
+type directKeyManager struct{}
+
+func (d *directKeyManager) Algorithm() string {
+	return string(AlgorithmDirect)
+}
+
+func (d *directKeyManager) EncryptKey(key interface{}, cekSize int) ([]byte, []byte, error) {
+	cek, err := d.checkKey(key, cekSize)
+	if err != nil {
+		return nil, nil, err
+	}
+	return cek, []byte{}, nil
+}
+
+func (d *directKeyManager) DecryptKey(encryptedKey []byte, key interface{}, cekSize int) ([]byte, error) {
+	// RFC 7516 section 5.2: the encrypted key must be empty with direct encryption
+	if len(encryptedKey) != 0 {
+		return nil, ErrDecryption
+	}
+	return d.checkKey(key, cekSize)
+}
+
+func (d *directKeyManager) checkKey(key interface{}, cekSize int) ([]byte, error) {
+	cek, ok := key.([]byte)
+	if !ok {
+		return nil, invalidKey(d.Algorithm(), "expected []byte")
+	}
+	if len(cek) != cekSize {
+		return nil, invalidKey(d.Algorithm(), "the content encryption requires a key of %d bytes with direct encryption, got %d", cekSize, len(cek))
+	}
+	return cek, nil
+}
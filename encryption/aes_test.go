@@ -0,0 +1,92 @@
+package encryption
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/hex"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func unhex(t *testing.T, s string) []byte {
+	ret, err := hex.DecodeString(s)
+	assert.Nil(t, err)
+	return ret
+}
+
+func TestKeyWrapRFC3394(t *testing.T) {
+	for _, tc := range []struct{ kek, key, wrapped string }{
+		// RFC 3394 section 4.1, 128 bits of key data with a 128-bit KEK
+		{"000102030405060708090A0B0C0D0E0F", "00112233445566778899AABBCCDDEEFF", "1FA68B0A8112B447AEF34BD8FB5A7B829D3E862371D2CFE5"},
+		// RFC 3394 section 4.6, 256 bits of key data with a 256-bit KEK
+		{"000102030405060708090A0B0C0D0E0F101112131415161718191A1B1C1D1E1F", "00112233445566778899AABBCCDDEEFF000102030405060708090A0B0C0D0E0F", "28C9F404C4B810F4CBCCB35CFB87F8263F5786E2D80ED326CBC7F0E71A99F43BFB988B9B7A02DD21"},
+	} {
+		wrapped, err := wrapKey(unhex(t, tc.kek), unhex(t, tc.key))
+		assert.Nil(t, err)
+		assert.Equal(t, unhex(t, tc.wrapped), wrapped)
+
+		key, err := unwrapKey(unhex(t, tc.kek), wrapped)
+		assert.Nil(t, err)
+		assert.Equal(t, unhex(t, tc.key), key)
+
+		wrapped[len(wrapped)-1] ^= 1
+		_, err = unwrapKey(unhex(t, tc.kek), wrapped)
+		assert.NotNil(t, err)
+	}
+}
+
+func TestKeyManagers(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.Nil(t, err)
+	for alg, key := range map[KeyAlgorithm][2]interface{}{
+		AlgorithmRSAOAEP:    {&rsaKey.PublicKey, rsaKey},
+		AlgorithmRSAOAEP256: {&rsaKey.PublicKey, rsaKey},
+		AlgorithmA128KW:     {make([]byte, 16), make([]byte, 16)},
+		AlgorithmA192KW:     {make([]byte, 24), make([]byte, 24)},
+		AlgorithmA256KW:     {make([]byte, 32), make([]byte, 32)},
+		AlgorithmDirect:     {make([]byte, 32), make([]byte, 32)},
+	} {
+		manager := alg.KeyManager()
+		assert.Equal(t, alg.String(), manager.Algorithm())
+		cek, encryptedKey, err := manager.EncryptKey(key[0], 32)
+		assert.Nil(t, err, alg.String())
+		assert.Len(t, cek, 32, alg.String())
+
+		decrypted, err := manager.DecryptKey(encryptedKey, key[1], 32)
+		assert.Nil(t, err, alg.String())
+		assert.Equal(t, cek, decrypted, alg.String())
+
+		// Tampering with the encrypted key is not reported as a key error
+		_, err = manager.DecryptKey(append(encryptedKey, 1), key[1], 32)
+		assert.True(t, errors.Is(err, ErrDecryption), alg.String())
+	}
+}
+
+func TestKeyManagersInvalidKey(t *testing.T) {
+	weak, err := rsa.GenerateKey(rand.Reader, 1024)
+	assert.Nil(t, err)
+	for alg, key := range map[KeyAlgorithm]interface{}{
+		AlgorithmRSAOAEP256: &weak.PublicKey,
+		AlgorithmRSAOAEP:    make([]byte, 16),
+		AlgorithmA128KW:     make([]byte, 32),
+		AlgorithmA256KW:     &weak.PublicKey,
+		AlgorithmDirect:     make([]byte, 16),
+	} {
+		_, _, err := alg.KeyManager().EncryptKey(key, 32)
+		assert.True(t, errors.Is(err, ErrInvalidKey), alg.String())
+	}
+	_, err = AlgorithmRSAOAEP.KeyManager().DecryptKey(nil, &weak.PublicKey, 32)
+	assert.True(t, errors.Is(err, ErrInvalidKey))
+}
+
+func TestDefaultFromKey(t *testing.T) {
+	assert.Equal(t, AlgorithmRSAOAEP256, DefaultFromKey(&rsa.PublicKey{}))
+	assert.Equal(t, AlgorithmA128KW, DefaultFromKey(make([]byte, 16)))
+	assert.Equal(t, AlgorithmA192KW, DefaultFromKey(make([]byte, 24)))
+	assert.Equal(t, AlgorithmA256KW, DefaultFromKey(make([]byte, 32)))
+	assert.Equal(t, KeyAlgorithm(""), DefaultFromKey(make([]byte, 64)))
+	assert.Equal(t, KeyAlgorithm(""), DefaultFromKey("secret"))
+	assert.Nil(t, KeyAlgorithm("RSA1_5").KeyManager())
+}
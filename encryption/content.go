@@ -0,0 +1,157 @@
+package encryption
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"encoding/binary"
+)
+
+const gcmIVSize = 12
+
+type gcmEncryptor struct {
+	alg  string
+	size int
+}
+
+func (g *gcmEncryptor) Algorithm() string {
+	return g.alg
+}
+
+func (g *gcmEncryptor) KeySize() int {
+	return g.size
+}
+
+func (g *gcmEncryptor) Encrypt(cek, aad, plaintext []byte) ([]byte, []byte, []byte, error) {
+	iv := make([]byte, gcmIVSize)
+	if _, err := rand.Read(iv); err != nil {
+		return nil, nil, nil, err
+	}
+	ciphertext, tag, err := g.encrypt(cek, iv, aad, plaintext)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return iv, ciphertext, tag, nil
+}
+
+func (g *gcmEncryptor) encrypt(cek, iv, aad, plaintext []byte) ([]byte, []byte, error) {
+	aead, err := g.newGCM(cek)
+	if err != nil {
+		return nil, nil, err
+	}
+	sealed := aead.Seal(nil, iv, plaintext, aad)
+	n := len(sealed) - aead.Overhead()
+	return sealed[:n:n], sealed[n:], nil
+}
+
+func (g *gcmEncryptor) Decrypt(cek, aad, iv, ciphertext, tag []byte) ([]byte, error) {
+	aead, err := g.newGCM(cek)
+	if err != nil {
+		return nil, err
+	}
+	if len(iv) != gcmIVSize || len(tag) != aead.Overhead() {
+		return nil, ErrDecryption
+	}
+	sealed := append(append(make([]byte, 0, len(ciphertext)+len(tag)), ciphertext...), tag...)
+	ret, err := aead.Open(sealed[:0], iv, sealed, aad)
+	if err != nil {
+		return nil, ErrDecryption
+	}
+	return ret, nil
+}
+
+func (g *gcmEncryptor) newGCM(cek []byte) (cipher.AEAD, error) {
+	if len(cek) != g.size {
+		return nil, invalidKey(g.alg, "%s requires a key of %d bytes, got %d", g.alg, g.size, len(cek))
+	}
+	block, err := aes.NewCipher(cek)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// cbcEncryptor implements AES-CBC with HMAC-SHA2 authentication as defined in RFC 7518 section 5.2.
+// The CEK is the HMAC key followed by the AES key, each of `size` bytes, and the tag is the first
+// `size` bytes of the HMAC.
+type cbcEncryptor struct {
+	alg  string
+	size int
+	hash crypto.Hash
+}
+
+func (c *cbcEncryptor) Algorithm() string {
+	return c.alg
+}
+
+func (c *cbcEncryptor) KeySize() int {
+	return c.size * 2
+}
+
+func (c *cbcEncryptor) Encrypt(cek, aad, plaintext []byte) ([]byte, []byte, []byte, error) {
+	iv := make([]byte, aes.BlockSize)
+	if _, err := rand.Read(iv); err != nil {
+		return nil, nil, nil, err
+	}
+	ciphertext, tag, err := c.encrypt(cek, iv, aad, plaintext)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return iv, ciphertext, tag, nil
+}
+
+func (c *cbcEncryptor) encrypt(cek, iv, aad, plaintext []byte) ([]byte, []byte, error) {
+	block, err := c.newCipher(cek)
+	if err != nil {
+		return nil, nil, err
+	}
+	n := aes.BlockSize - len(plaintext)%aes.BlockSize
+	ciphertext := append(append(make([]byte, 0, len(plaintext)+n), plaintext...), bytes.Repeat([]byte{byte(n)}, n)...)
+	cipher.NewCBCEncrypter(block, iv).CryptBlocks(ciphertext, ciphertext)
+	return ciphertext, c.tag(cek, aad, iv, ciphertext), nil
+}
+
+func (c *cbcEncryptor) Decrypt(cek, aad, iv, ciphertext, tag []byte) ([]byte, error) {
+	block, err := c.newCipher(cek)
+	if err != nil {
+		return nil, err
+	}
+	if len(iv) != aes.BlockSize || len(ciphertext) == 0 || len(ciphertext)%aes.BlockSize != 0 {
+		return nil, ErrDecryption
+	}
+	// The tag is verified before decrypting, so the padding is never checked on forged content
+	if !hmac.Equal(tag, c.tag(cek, aad, iv, ciphertext)) {
+		return nil, ErrDecryption
+	}
+
+	ret := make([]byte, len(ciphertext))
+	cipher.NewCBCDecrypter(block, iv).CryptBlocks(ret, ciphertext)
+	n := int(ret[len(ret)-1])
+	if n == 0 || n > aes.BlockSize || !bytes.Equal(ret[len(ret)-n:], bytes.Repeat([]byte{byte(n)}, n)) {
+		return nil, ErrDecryption
+	}
+	return ret[:len(ret)-n], nil
+}
+
+func (c *cbcEncryptor) newCipher(cek []byte) (cipher.Block, error) {
+	if len(cek) != c.KeySize() {
+		return nil, invalidKey(c.alg, "%s requires a key of %d bytes, got %d", c.alg, c.KeySize(), len(cek))
+	}
+	return aes.NewCipher(cek[c.size:])
+}
+
+// tag computes the authentication tag over the additional data, the IV, the ciphertext and the
+// additional data length in bits
+func (c *cbcEncryptor) tag(cek, aad, iv, ciphertext []byte) []byte {
+	var al [8]byte
+	binary.BigEndian.PutUint64(al[:], uint64(len(aad))*8)
+	h := hmac.New(c.hash.New, cek[:c.size])
+	h.Write(aad)
+	h.Write(iv)
+	h.Write(ciphertext)
+	h.Write(al[:])
+	return h.Sum(nil)[:c.size]
+}
@@ -0,0 +1,71 @@
+package encryption
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCBCHS256RFC7518(t *testing.T) {
+	// RFC 7518 appendix B.1
+	key := unhex(t, "000102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f")
+	iv := unhex(t, "1af38c2dc2b96ffdd86694092341bc04")
+	plaintext := []byte("A cipher system must not be required to be secret, and it must be able to fall into the hands of the enemy without inconvenience")
+	aad := []byte("The second principle of Auguste Kerckhoffs")
+
+	encryptor := EncryptionA128CBCHS256.Encryptor().(*cbcEncryptor)
+	ciphertext, tag, err := encryptor.encrypt(key, iv, aad, plaintext)
+	assert.Nil(t, err)
+	assert.Equal(t, unhex(t, "c80edfa32ddf39d5ef00c0b468834279a2e46a1b8049f792f76bfe54b903a9c9a94ac9b47ad2655c5f10f9aef71427e2"+
+		"fc6f9b3f399a221489f16362c703233609d45ac69864e3321cf82935ac4096c86e133314c54019e8ca7980dfa4b9cf1b"+
+		"384c486f3a54c51078158ee5d79de59fbd34d848b3d69550a67646344427ade54b8851ffb598f7f80074b9473c82e2db"), ciphertext)
+	assert.Equal(t, unhex(t, "652c3fa36b0a7c5b3219fab3a30bc1c4"), tag)
+
+	decrypted, err := encryptor.Decrypt(key, aad, iv, ciphertext, tag)
+	assert.Nil(t, err)
+	assert.Equal(t, plaintext, decrypted)
+}
+
+func TestEncryptors(t *testing.T) {
+	plaintext, aad := []byte("payload"), []byte("aad")
+	for _, enc := range []ContentEncryption{
+		EncryptionA128GCM, EncryptionA192GCM, EncryptionA256GCM,
+		EncryptionA128CBCHS256, EncryptionA192CBCHS384, EncryptionA256CBCHS512,
+	} {
+		encryptor := enc.Encryptor()
+		assert.Equal(t, enc.String(), encryptor.Algorithm())
+		cek := make([]byte, encryptor.KeySize())
+		iv, ciphertext, tag, err := encryptor.Encrypt(cek, aad, plaintext)
+		assert.Nil(t, err, enc.String())
+
+		decrypted, err := encryptor.Decrypt(cek, aad, iv, ciphertext, tag)
+		assert.Nil(t, err, enc.String())
+		assert.Equal(t, plaintext, decrypted, enc.String())
+
+		// Any change to the additional data, IV, ciphertext or tag fails the authentication
+		for i, args := range [][4][]byte{
+			{[]byte("aaD"), iv, ciphertext, tag},
+			{aad, flip(iv), ciphertext, tag},
+			{aad, iv, flip(ciphertext), tag},
+			{aad, iv, ciphertext, flip(tag)},
+			{aad, iv, ciphertext, tag[:len(tag)-1]},
+			{aad, iv[:len(iv)-1], ciphertext, tag},
+			{aad, iv, nil, tag},
+		} {
+			_, err := encryptor.Decrypt(cek, args[0], args[1], args[2], args[3])
+			assert.True(t, errors.Is(err, ErrDecryption), enc.String(), i)
+		}
+
+		_, _, _, err = encryptor.Encrypt(cek[1:], aad, plaintext)
+		assert.True(t, errors.Is(err, ErrInvalidKey), enc.String())
+	}
+	assert.Nil(t, ContentEncryption("A128CTR").Encryptor())
+}
+
+// flip returns a copy of the data with the last bit flipped
+func flip(data []byte) []byte {
+	ret := append([]byte{}, data...)
+	ret[len(ret)-1] ^= 1
+	return ret
+}
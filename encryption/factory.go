@@ -0,0 +1,98 @@
+package encryption
+
+import (
+	"crypto"
+	"crypto/rsa"
+	_ "crypto/sha1"
+	_ "crypto/sha256"
+	_ "crypto/sha512"
+)
+
+const (
+	AlgorithmDirect     KeyAlgorithm = "dir"
+	AlgorithmRSAOAEP    KeyAlgorithm = "RSA-OAEP"
+	AlgorithmRSAOAEP256 KeyAlgorithm = "RSA-OAEP-256"
+	AlgorithmA128KW     KeyAlgorithm = "A128KW"
+	AlgorithmA192KW     KeyAlgorithm = "A192KW"
+	AlgorithmA256KW     KeyAlgorithm = "A256KW"
+
+	EncryptionA128GCM      ContentEncryption = "A128GCM"
+	EncryptionA192GCM      ContentEncryption = "A192GCM"
+	EncryptionA256GCM      ContentEncryption = "A256GCM"
+	EncryptionA128CBCHS256 ContentEncryption = "A128CBC-HS256"
+	EncryptionA192CBCHS384 ContentEncryption = "A192CBC-HS384"
+	EncryptionA256CBCHS512 ContentEncryption = "A256CBC-HS512"
+)
+
+// KeyAlgorithm indicates the key management algorithm ("alg") used to determine the CEK
+type KeyAlgorithm string
+
+// KeyManager returns the proper key manager tied to the algorithm. If not found, returns nil
+func (a KeyAlgorithm) KeyManager() IKeyManager {
+	if ret, ok := keyManagers[a]; ok {
+		return ret
+	}
+	return nil
+}
+
+// String returns the string value of this instance
+func (a KeyAlgorithm) String() string {
+	return string(a)
+}
+
+// ContentEncryption indicates the content encryption algorithm ("enc") used to encrypt the payload
+type ContentEncryption string
+
+// Encryptor returns the proper content encryptor tied to the algorithm. If not found, returns nil
+func (e ContentEncryption) Encryptor() IContentEncryptor {
+	if ret, ok := encryptors[e]; ok {
+		return ret
+	}
+	return nil
+}
+
+// String returns the string value of this instance
+func (e ContentEncryption) String() string {
+	return string(e)
+}
+
+var keyManagers = map[KeyAlgorithm]IKeyManager{
+	AlgorithmDirect: &directKeyManager{},
+
+	AlgorithmRSAOAEP:    &rsaKeyManager{alg: "RSA-OAEP", hash: crypto.SHA1},
+	AlgorithmRSAOAEP256: &rsaKeyManager{alg: "RSA-OAEP-256", hash: crypto.SHA256},
+
+	AlgorithmA128KW: &aesKeyManager{alg: "A128KW", size: 16},
+	AlgorithmA192KW: &aesKeyManager{alg: "A192KW", size: 24},
+	AlgorithmA256KW: &aesKeyManager{alg: "A256KW", size: 32},
+}
+
+var encryptors = map[ContentEncryption]IContentEncryptor{
+	EncryptionA128GCM: &gcmEncryptor{alg: "A128GCM", size: 16},
+	EncryptionA192GCM: &gcmEncryptor{alg: "A192GCM", size: 24},
+	EncryptionA256GCM: &gcmEncryptor{alg: "A256GCM", size: 32},
+
+	EncryptionA128CBCHS256: &cbcEncryptor{alg: "A128CBC-HS256", size: 16, hash: crypto.SHA256},
+	EncryptionA192CBCHS384: &cbcEncryptor{alg: "A192CBC-HS384", size: 24, hash: crypto.SHA384},
+	EncryptionA256CBCHS512: &cbcEncryptor{alg: "A256CBC-HS512", size: 32, hash: crypto.SHA512},
+}
+
+// DefaultFromKey returns the key management algorithm used by default with the provided key:
+// RSA-OAEP-256 for RSA keys and the AES key wrap matching the size of 16, 24 or 32 byte secrets.
+// Direct encryption is never the default, it must be selected explicitly.
+func DefaultFromKey(key interface{}) KeyAlgorithm {
+	switch k := key.(type) {
+	case *rsa.PublicKey, *rsa.PrivateKey:
+		return AlgorithmRSAOAEP256
+	case []byte:
+		switch len(k) {
+		case 16:
+			return AlgorithmA128KW
+		case 24:
+			return AlgorithmA192KW
+		case 32:
+			return AlgorithmA256KW
+		}
+	}
+	return ""
+}
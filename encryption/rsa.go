@@ -0,0 +1,70 @@
+package encryption
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+)
+
+// minRSABits is the minimum RSA modulus size accepted to encrypt and decrypt the CEK
+const minRSABits = 2048
+
+type rsaKeyManager struct {
+	alg  string
+	hash crypto.Hash
+}
+
+func (r *rsaKeyManager) Algorithm() string {
+	return r.alg
+}
+
+func (r *rsaKeyManager) EncryptKey(key interface{}, cekSize int) ([]byte, []byte, error) {
+	var pub *rsa.PublicKey
+	switch k := key.(type) {
+	case *rsa.PublicKey:
+		pub = k
+	case *rsa.PrivateKey:
+		pub = &k.PublicKey
+	default:
+		return nil, nil, invalidKey(r.alg, "expected *rsa.PublicKey")
+	}
+	if err := r.checkKey(pub); err != nil {
+		return nil, nil, err
+	}
+
+	cek := make([]byte, cekSize)
+	if _, err := rand.Read(cek); err != nil {
+		return nil, nil, err
+	}
+	encryptedKey, err := rsa.EncryptOAEP(r.hash.New(), rand.Reader, pub, cek, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	return cek, encryptedKey, nil
+}
+
+func (r *rsaKeyManager) DecryptKey(encryptedKey []byte, key interface{}, cekSize int) ([]byte, error) {
+	priv, ok := key.(*rsa.PrivateKey)
+	if !ok {
+		return nil, invalidKey(r.alg, "expected *rsa.PrivateKey")
+	}
+	if err := r.checkKey(&priv.PublicKey); err != nil {
+		return nil, err
+	}
+
+	cek, err := rsa.DecryptOAEP(r.hash.New(), nil, priv, encryptedKey, nil)
+	if err != nil || len(cek) != cekSize {
+		return nil, ErrDecryption
+	}
+	return cek, nil
+}
+
+func (r *rsaKeyManager) checkKey(key *rsa.PublicKey) error {
+	if key.N == nil {
+		return invalidKey(r.alg, "RSA modulus is nil")
+	}
+	if key.N.BitLen() < minRSABits {
+		return invalidKey(r.alg, "%s requires an RSA key of at least %d bits, got %d", r.alg, minRSABits, key.N.BitLen())
+	}
+	return nil
+}
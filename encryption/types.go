@@ -0,0 +1,58 @@
+package encryption
+
+import (
+	"errors"
+	"fmt"
+)
+
+// IKeyManager determines the content encryption key (CEK) of an encrypted token and produces the
+// encrypted key carried in the token, as defined by the "alg" header of a JWE (RFC 7518 section 4)
+type IKeyManager interface {
+	// EncryptKey generates a CEK of the provided size and returns it with its encrypted form. With
+	// direct encryption the key itself is the CEK and the encrypted key is empty.
+	EncryptKey(key interface{}, cekSize int) (cek, encryptedKey []byte, err error)
+	// DecryptKey recovers the CEK of the provided size from the encrypted key
+	DecryptKey(encryptedKey []byte, key interface{}, cekSize int) ([]byte, error)
+	Algorithm() string
+}
+
+// IContentEncryptor encrypts and authenticates the payload of an encrypted token with the CEK, as
+// defined by the "enc" header of a JWE (RFC 7518 section 5)
+type IContentEncryptor interface {
+	// Encrypt encrypts the plaintext and authenticates it along with the additional data, returns
+	// the generated initialization vector, the ciphertext and the authentication tag
+	Encrypt(cek, aad, plaintext []byte) (iv, ciphertext, tag []byte, err error)
+	// Decrypt verifies the authentication tag and returns the plaintext
+	Decrypt(cek, aad, iv, ciphertext, tag []byte) ([]byte, error)
+	// KeySize returns the size in bytes of the CEK
+	KeySize() int
+	Algorithm() string
+}
+
+var (
+	// ErrInvalidKey is the error all key validation errors match with `errors.Is`
+	ErrInvalidKey = errors.New("invalid key")
+	// ErrDecryption is returned when the encrypted key or the content cannot be decrypted or
+	// authenticated. The cause is deliberately not disclosed.
+	ErrDecryption = errors.New("decryption failed")
+)
+
+// InvalidKeyError indicates the provided key is of the wrong type or size for the algorithm
+type InvalidKeyError struct {
+	Algorithm string
+	Message   string
+}
+
+// Error returns the error message
+func (e *InvalidKeyError) Error() string {
+	return e.Message
+}
+
+// Is allows `errors.Is(err, ErrInvalidKey)` to match any *InvalidKeyError
+func (e *InvalidKeyError) Is(target error) bool {
+	return target == ErrInvalidKey
+}
+
+func invalidKey(alg, format string, args ...interface{}) error {
+	return &InvalidKeyError{Algorithm: alg, Message: "invalid key, " + fmt.Sprintf(format, args...)}
+}
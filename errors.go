@@ -13,6 +13,22 @@ const (
 	ErrNotBefore     // Not Before date is in the future
 	ErrWrongIssuer   // Issuer validation failed
 	ErrWrongAudience // Audience validation failed
+
+	ErrUnsecuredToken     // Token is unsecured ("alg": "none") and signing.UnsafeAllowNone was not provided
+	ErrInvalidCertificate // The x5c certificate chain is missing, malformed or not trusted
+	ErrKeyResolution      // The key to validate the signature or decrypt the token could not be resolved
+	ErrCriticalHeader     // A critical header extension (crit) is not supported or was rejected
+	ErrTokenType          // The token type (typ) is not one of the accepted types
+	ErrLimitExceeded      // The token exceeds one of the configured size or structure limits
+	ErrStrictDecoding     // The token is not canonically encoded (strict decoding only)
+	ErrMalformedToken     // The token is not made of three base64url encoded segments, five if encrypted
+	ErrInvalidSignature   // The signature does not match the token
+	ErrCanceled           // The verification was canceled before the token was processed
+	ErrRevoked            // The token was revoked, or the revocation store could not be checked
+	ErrReplayed           // The single-use token was already presented, or cannot be tracked
+	ErrRefreshReused      // The refresh token was already exchanged or its family was revoked
+	ErrDecryptionFailed   // The encrypted token could not be decrypted or its content is not authentic
+	ErrEncryptAlgorithm   // The key management or content encryption algorithm is not recognized
 )
 
 func newError(t ErrorType, args ...interface{}) *Error {
@@ -0,0 +1,15 @@
+module github.com/jucardi/go-jwt
+
+go 1.24
+
+require (
+	github.com/golang/protobuf v1.5.4
+	github.com/stretchr/testify v1.9.0
+	google.golang.org/protobuf v1.33.0
+)
+
+require (
+	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
@@ -0,0 +1,121 @@
+package jwt
+
+import "time"
+
+// NewGrant creates a Grant for the provided signed token and its claims
+//
+//   {token}  - The signed token string
+//   {claims} - The claims of the token
+//
+func NewGrant(token string, claims *ExtendedClaims) *Grant {
+	ret := &Grant{Token: token}
+	if claims != nil {
+		ret.Type = string(claims.Type)
+		if claims.ExpiresAt != nil {
+			ret.Exp = claims.ExpiresAt.UTC().Format(time.RFC3339)
+		}
+	}
+	return ret
+}
+
+// ExpiresAt parses the ISO 8601 expiration of the grant. Returns the zero time if the grant has no
+// expiration.
+func (m *Grant) ExpiresAt() (time.Time, error) {
+	if m == nil || m.Exp == "" {
+		return time.Time{}, nil
+	}
+	return time.Parse(time.RFC3339, m.Exp)
+}
+
+// TokenType returns the type of the granted token
+func (m *Grant) TokenType() TokenType {
+	return TokenType(m.GetType())
+}
+
+// Grants returns the Grant of the access token and the Grant of the refresh token of the pair
+func (p *TokenPair) Grants() (access, refresh *Grant) {
+	return NewGrant(p.AccessToken, p.AccessClaims), NewGrant(p.RefreshToken, p.RefreshClaims)
+}
+
+// TokenTypeBearer is the OAuth 2.0 "token_type" of bearer tokens (RFC 6750)
+const TokenTypeBearer = "Bearer"
+
+// NewTokenResponse creates an OAuth 2.0 token response from the Grant of the access token and
+// optionally the Grants of the refresh token and the OpenID Connect ID token. The "expires_in"
+// value is computed from the expiration of the access token relative to the current time.
+//
+//   {access} - The Grant of the access token
+//   {grants} - (optional) The Grant of the refresh token and the Grant of the ID token, told apart
+//              by their type: a Grant of type `TokenTypeID` sets "id_token", any other one sets
+//              "refresh_token"
+//
+func NewTokenResponse(access *Grant, grants ...*Grant) (*TokenResponse, error) {
+	if access == nil {
+		return nil, newError(ErrNilToken, "failed to create token response, access grant is nil")
+	}
+	exp, err := access.ExpiresAt()
+	if err != nil {
+		return nil, newErrorf(ErrUnmarshalFailed, "invalid grant expiration '%s', %s", access.Exp, err.Error())
+	}
+	ret := &TokenResponse{
+		AccessToken: access.Token,
+		TokenType:   TokenTypeBearer,
+	}
+	if !exp.IsZero() {
+		if ret.ExpiresIn = int64(time.Until(exp) / time.Second); ret.ExpiresIn < 0 {
+			ret.ExpiresIn = 0
+		}
+	}
+	for _, g := range grants {
+		switch {
+		case g == nil:
+		case g.TokenType() == TokenTypeID:
+			ret.IdToken = g.Token
+		default:
+			ret.RefreshToken = g.Token
+		}
+	}
+	return ret, nil
+}
+
+// TokenResponse creates an OAuth 2.0 token response from the pair, including the scope of the
+// access token
+func (p *TokenPair) TokenResponse() (*TokenResponse, error) {
+	access, refresh := p.Grants()
+	ret, err := NewTokenResponse(access, refresh)
+	if err != nil {
+		return nil, err
+	}
+	if p.AccessClaims != nil {
+		ret.Scope = p.AccessClaims.Scope
+	}
+	return ret, nil
+}
+
+// AccessGrant returns the Grant of the access token of the response, with the expiration computed
+// from "expires_in" relative to the current time
+func (m *TokenResponse) AccessGrant() *Grant {
+	ret := &Grant{Token: m.GetAccessToken(), Type: string(TokenTypeAccess)}
+	if m.GetExpiresIn() > 0 {
+		ret.Exp = time.Now().Add(time.Duration(m.GetExpiresIn()) * time.Second).UTC().Format(time.RFC3339)
+	}
+	return ret
+}
+
+// RefreshGrant returns the Grant of the refresh token of the response, without expiration since
+// token responses do not include it. Returns nil if the response has no refresh token.
+func (m *TokenResponse) RefreshGrant() *Grant {
+	if m.GetRefreshToken() == "" {
+		return nil
+	}
+	return &Grant{Token: m.GetRefreshToken(), Type: string(TokenTypeRefresh)}
+}
+
+// IDGrant returns the Grant of the OpenID Connect ID token of the response, without expiration
+// since token responses do not include it. Returns nil if the response has no ID token.
+func (m *TokenResponse) IDGrant() *Grant {
+	if m.GetIdToken() == "" {
+		return nil
+	}
+	return &Grant{Token: m.GetIdToken(), Type: string(TokenTypeID)}
+}
@@ -82,19 +82,117 @@ func (m *Grant) GetType() string {
 	return ""
 }
 
+// TokenResponse is the successful response of an OAuth 2.0 token endpoint as defined in RFC 6749
+// section 5.1, so HTTP endpoints and gRPC services return identical token responses. The json_name
+// options keep the RFC member names when marshaled with protojson or jsonpb, which otherwise use
+// the lowerCamelCase names.
+//
+// swagger: model TokenResponse
+type TokenResponse struct {
+	// AccessToken is the access token string.
+	AccessToken string `protobuf:"bytes,1,opt,name=access_token,proto3" json:"access_token,omitempty"`
+	// TokenType is the type of the access token, usually "Bearer"
+	TokenType string `protobuf:"bytes,2,opt,name=token_type,proto3" json:"token_type,omitempty"`
+	// ExpiresIn is the lifetime in seconds of the access token
+	ExpiresIn int64 `protobuf:"varint,3,opt,name=expires_in,proto3" json:"expires_in,omitempty"`
+	// RefreshToken is the optional refresh token string to obtain new access tokens
+	RefreshToken string `protobuf:"bytes,4,opt,name=refresh_token,proto3" json:"refresh_token,omitempty"`
+	// Scope is the optional space separated scope of the access token
+	Scope string `protobuf:"bytes,5,opt,name=scope,proto3" json:"scope,omitempty"`
+	// IdToken is the optional OpenID Connect ID token
+	IdToken              string   `protobuf:"bytes,6,opt,name=id_token,proto3" json:"id_token,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-" yaml:"-" gorm:"-" bson:"-"`
+	XXX_unrecognized     []byte   `json:"-" yaml:"-" gorm:"-" bson:"-"`
+	XXX_sizecache        int32    `json:"-" yaml:"-" gorm:"-" bson:"-"`
+}
+
+func (m *TokenResponse) Reset()         { *m = TokenResponse{} }
+func (m *TokenResponse) String() string { return proto.CompactTextString(m) }
+func (*TokenResponse) ProtoMessage()    {}
+func (*TokenResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_d8d80872b3060482, []int{1}
+}
+
+func (m *TokenResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_TokenResponse.Unmarshal(m, b)
+}
+func (m *TokenResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_TokenResponse.Marshal(b, m, deterministic)
+}
+func (m *TokenResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_TokenResponse.Merge(m, src)
+}
+func (m *TokenResponse) XXX_Size() int {
+	return xxx_messageInfo_TokenResponse.Size(m)
+}
+func (m *TokenResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_TokenResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_TokenResponse proto.InternalMessageInfo
+
+func (m *TokenResponse) GetAccessToken() string {
+	if m != nil {
+		return m.AccessToken
+	}
+	return ""
+}
+
+func (m *TokenResponse) GetTokenType() string {
+	if m != nil {
+		return m.TokenType
+	}
+	return ""
+}
+
+func (m *TokenResponse) GetExpiresIn() int64 {
+	if m != nil {
+		return m.ExpiresIn
+	}
+	return 0
+}
+
+func (m *TokenResponse) GetRefreshToken() string {
+	if m != nil {
+		return m.RefreshToken
+	}
+	return ""
+}
+
+func (m *TokenResponse) GetScope() string {
+	if m != nil {
+		return m.Scope
+	}
+	return ""
+}
+
+func (m *TokenResponse) GetIdToken() string {
+	if m != nil {
+		return m.IdToken
+	}
+	return ""
+}
+
 func init() {
 	proto.RegisterType((*Grant)(nil), "jwt.Grant")
+	proto.RegisterType((*TokenResponse)(nil), "jwt.TokenResponse")
 }
 
 func init() { proto.RegisterFile("grant.proto", fileDescriptor_d8d80872b3060482) }
 
 var fileDescriptor_d8d80872b3060482 = []byte{
-	// 98 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xe2, 0xe2, 0x4e, 0x2f, 0x4a, 0xcc,
-	0x2b, 0xd1, 0x2b, 0x28, 0xca, 0x2f, 0xc9, 0x17, 0x62, 0xce, 0x2a, 0x2f, 0x51, 0x72, 0xe6, 0x62,
-	0x75, 0x07, 0x89, 0x09, 0x89, 0x70, 0xb1, 0x96, 0xe4, 0x67, 0xa7, 0xe6, 0x49, 0x30, 0x2a, 0x30,
-	0x6a, 0x70, 0x06, 0x41, 0x38, 0x42, 0x02, 0x5c, 0xcc, 0xa9, 0x15, 0x05, 0x12, 0x4c, 0x60, 0x31,
-	0x10, 0x53, 0x48, 0x88, 0x8b, 0xa5, 0xa4, 0xb2, 0x20, 0x55, 0x82, 0x19, 0x2c, 0x04, 0x66, 0x27,
-	0xb1, 0x81, 0x0d, 0x34, 0x06, 0x04, 0x00, 0x00, 0xff, 0xff, 0xc6, 0x01, 0x70, 0xae, 0x5f, 0x00,
-	0x00, 0x00,
+	// 198 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x54, 0x90, 0xc1, 0xca, 0xc2, 0x30,
+	0x10, 0x84, 0xe9, 0x9f, 0xb6, 0xfc, 0xae, 0x16, 0x64, 0xf1, 0x10, 0x3c, 0x88, 0x14, 0x0f, 0x9e,
+	0xbc, 0xf8, 0x08, 0x1e, 0xbc, 0x17, 0xef, 0xa5, 0xd6, 0x55, 0xab, 0x90, 0x84, 0x24, 0x60, 0x7d,
+	0x46, 0x5f, 0x4a, 0xb2, 0x2d, 0xd2, 0xde, 0x66, 0xbe, 0x4d, 0x66, 0xd8, 0x85, 0xe9, 0xcd, 0x56,
+	0xca, 0xef, 0x8c, 0xd5, 0x5e, 0xa3, 0x78, 0xbc, 0x7c, 0x7e, 0x80, 0xe4, 0x18, 0x18, 0x2e, 0x20,
+	0xf1, 0xfa, 0x49, 0x4a, 0x46, 0xeb, 0x68, 0x3b, 0x29, 0x3a, 0x83, 0x73, 0x10, 0xd4, 0x1a, 0xf9,
+	0xc7, 0x2c, 0x48, 0x44, 0x88, 0xfd, 0xdb, 0x90, 0x14, 0x8c, 0x58, 0xe7, 0x9f, 0x08, 0xb2, 0x53,
+	0x78, 0x5f, 0x90, 0x33, 0x5a, 0x39, 0xc2, 0x1c, 0x66, 0x55, 0x5d, 0x93, 0x73, 0xe5, 0x30, 0x74,
+	0xc4, 0x70, 0x05, 0xc0, 0xa2, 0xe4, 0xbc, 0xae, 0x62, 0x40, 0xc2, 0x9c, 0x5a, 0xd3, 0x58, 0x72,
+	0x65, 0xa3, 0xb8, 0x4f, 0x14, 0x03, 0x82, 0x1b, 0xc8, 0x2c, 0x5d, 0x2d, 0xb9, 0x7b, 0x5f, 0x12,
+	0x73, 0xc4, 0x18, 0x86, 0xbd, 0x5c, 0xad, 0x0d, 0xc9, 0xa4, 0xdb, 0x8b, 0x0d, 0x2e, 0xe1, 0xbf,
+	0xb9, 0xf4, 0xdf, 0x52, 0x1e, 0xfc, 0xfc, 0x39, 0xe5, 0xf3, 0xec, 0xbf, 0x03, 0x00, 0x2e, 0x59,
+	0x35, 0xd1, 0x2d, 0x01, 0x00, 0x00,
 }
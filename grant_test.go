@@ -0,0 +1,112 @@
+package jwt
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/jsonpb"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTokenResponseRoundTrip(t *testing.T) {
+	issuer := NewRefreshIssuer(NewIssuer("issuer", testSecret), nil)
+	pair, err := issuer.IssuePair(testClaims())
+	assert.Nil(t, err)
+
+	response, err := pair.TokenResponse()
+	assert.Nil(t, err)
+	assert.Equal(t, pair.AccessToken, response.AccessToken)
+	assert.Equal(t, pair.RefreshToken, response.RefreshToken)
+	assert.Equal(t, TokenTypeBearer, response.TokenType)
+	assert.Equal(t, "read write", response.Scope)
+	assert.InDelta(t, defaultAccessTTL.Seconds(), response.ExpiresIn, 1)
+
+	// encoding/json and jsonpb both produce the RFC 6749 section 5.1 member names
+	stdData, err := json.Marshal(response)
+	assert.Nil(t, err)
+	var pbData bytes.Buffer
+	assert.Nil(t, (&jsonpb.Marshaler{}).Marshal(&pbData, response))
+
+	for _, data := range [][]byte{stdData, pbData.Bytes()} {
+		members := map[string]interface{}{}
+		assert.Nil(t, json.Unmarshal(data, &members))
+		for _, name := range []string{"access_token", "token_type", "expires_in", "refresh_token", "scope"} {
+			assert.Contains(t, members, name, string(data))
+		}
+
+		parsed := &TokenResponse{}
+		assert.Nil(t, jsonpb.Unmarshal(bytes.NewReader(data), parsed))
+		assert.Equal(t, response.AccessToken, parsed.AccessToken)
+		assert.Equal(t, response.ExpiresIn, parsed.ExpiresIn)
+
+		access := parsed.AccessGrant()
+		assert.Equal(t, pair.AccessToken, access.Token)
+		assert.Equal(t, TokenTypeAccess, access.TokenType())
+		exp, err := access.ExpiresAt()
+		assert.Nil(t, err)
+		assert.WithinDuration(t, pair.AccessClaims.ExpiresAt.Time, exp, 2*time.Second)
+
+		refresh := parsed.RefreshGrant()
+		assert.Equal(t, pair.RefreshToken, refresh.Token)
+		assert.Equal(t, TokenTypeRefresh, refresh.TokenType())
+	}
+}
+
+func TestNewTokenResponse(t *testing.T) {
+	_, err := NewTokenResponse(nil)
+	assert.True(t, ErrNilToken.IsType(err))
+
+	_, err = NewTokenResponse(&Grant{Token: "token", Exp: "tomorrow"})
+	assert.True(t, ErrUnmarshalFailed.IsType(err))
+
+	response, err := NewTokenResponse(&Grant{Token: "token", Exp: time.Now().Add(-time.Hour).Format(time.RFC3339)})
+	assert.Nil(t, err)
+	assert.Equal(t, int64(0), response.ExpiresIn)
+	assert.Nil(t, response.RefreshGrant())
+
+	response, err = NewTokenResponse(&Grant{Token: "token"})
+	assert.Nil(t, err)
+	assert.Equal(t, "", response.AccessGrant().Exp)
+}
+
+func TestTokenResponseIDToken(t *testing.T) {
+	issuer := NewIssuer("issuer", testSecret)
+	access, err := issuer.Issue(TokenTypeAccess, &ExtendedClaims{Subject: "alice"})
+	assert.Nil(t, err)
+	id, err := issuer.Issue(TokenTypeID, &ExtendedClaims{Subject: "alice"})
+	assert.Nil(t, err)
+	assert.Equal(t, TokenTypeID, id.TokenType())
+	refresh := &Grant{Token: "refresh", Type: string(TokenTypeRefresh)}
+
+	// The grants are told apart by type, whatever their order
+	for _, grants := range [][]*Grant{{refresh, id}, {id, refresh}, {nil, id, refresh}} {
+		response, err := NewTokenResponse(access, grants...)
+		assert.Nil(t, err)
+		assert.Equal(t, id.Token, response.IdToken)
+		assert.Equal(t, "refresh", response.RefreshToken)
+	}
+
+	response, err := NewTokenResponse(access, id)
+	assert.Nil(t, err)
+	assert.Empty(t, response.RefreshToken)
+	assert.Nil(t, response.RefreshGrant())
+
+	data, err := json.Marshal(response)
+	assert.Nil(t, err)
+	members := map[string]interface{}{}
+	assert.Nil(t, json.Unmarshal(data, &members))
+	assert.Equal(t, id.Token, members["id_token"])
+
+	parsed := &TokenResponse{}
+	assert.Nil(t, jsonpb.Unmarshal(bytes.NewReader(data), parsed))
+	assert.Equal(t, &Grant{Token: id.Token, Type: string(TokenTypeID)}, parsed.IDGrant())
+
+	claims := &ExtendedClaims{}
+	_, err = ParseAndValidate(parsed.IDGrant().Token, claims, testSecret)
+	assert.Nil(t, err)
+	assert.Equal(t, TokenTypeID, claims.Type)
+
+	assert.Nil(t, (&TokenResponse{AccessToken: "token"}).IDGrant())
+}
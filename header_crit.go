@@ -0,0 +1,60 @@
+package jwt
+
+// registeredHeaders are the header parameters defined by RFC 7515 and RFC 7516, which must not be
+// listed in "crit"
+var registeredHeaders = map[string]bool{
+	headerAlgKey:     true,
+	headerJKUKey:     true,
+	headerJWKKey:     true,
+	headerKeyIDKey:   true,
+	headerX5UKey:     true,
+	headerX5CKey:     true,
+	headerX5TKey:     true,
+	headerX5TS256Key: true,
+	headerTypeKey:    true,
+	headerCtyKey:     true,
+	headerCritKey:    true,
+	headerEncKey:     true,
+	headerZipKey:     true,
+}
+
+// verifyCritical enforces the "crit" header as defined in RFC 7515 section 4.1.11
+func verifyCritical(h TokenHeader, handlers map[string]CriticalHeaderHandler) error {
+	val, ok := h[headerCritKey]
+	if !ok {
+		return nil
+	}
+	crit := h.Critical()
+	if crit == nil {
+		return newErrorf(ErrCriticalHeader, "invalid '%s' header %v, expected a non empty array of strings", headerCritKey, val)
+	}
+	if len(crit) == 0 {
+		return newErrorf(ErrCriticalHeader, "invalid '%s' header, the list of extensions must not be empty", headerCritKey)
+	}
+
+	seen := map[string]bool{}
+	for _, name := range crit {
+		if seen[name] {
+			return newErrorf(ErrCriticalHeader, "critical header extension '%s' is listed more than once", name)
+		}
+		seen[name] = true
+		if registeredHeaders[name] {
+			return newErrorf(ErrCriticalHeader, "'%s' is a registered header and must not be listed as critical", name)
+		}
+		handler, ok := handlers[name]
+		if !ok {
+			return newErrorf(ErrCriticalHeader, "critical header extension '%s' is not supported", name)
+		}
+		value, ok := h[name]
+		if !ok {
+			return newErrorf(ErrCriticalHeader, "critical header extension '%s' is missing from the header", name)
+		}
+		if handler == nil {
+			continue
+		}
+		if err := handler(value); err != nil {
+			return newErrorf(ErrCriticalHeader, "critical header extension '%s' rejected, %s", name, err.Error())
+		}
+	}
+	return nil
+}
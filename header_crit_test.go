@@ -0,0 +1,69 @@
+package jwt
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const critClaims = `{"sub":"alice"}`
+
+func TestCriticalHeader(t *testing.T) {
+	var received interface{}
+	handler := WithCriticalHeader("exp-ext", func(value interface{}) error {
+		received = value
+		return nil
+	})
+
+	// Names the caller understands are accepted and their values handed over
+	token := signRaw(t, `{"alg":"HS256","typ":"JWT","crit":["exp-ext"],"exp-ext":42}`, critClaims)
+	_, err := ParseAndValidate(token, &ExtendedClaims{}, testSecret, handler)
+	assert.Nil(t, err)
+	assert.Equal(t, float64(42), received)
+
+	// A nil handler accepts any value
+	_, err = ParseAndValidate(token, &ExtendedClaims{}, testSecret, WithCriticalHeader("exp-ext", nil))
+	assert.Nil(t, err)
+
+	// Unknown names are rejected
+	_, err = ParseAndValidate(token, &ExtendedClaims{}, testSecret)
+	assert.True(t, ErrCriticalHeader.IsType(err))
+	assert.Contains(t, err.Error(), "not supported")
+
+	// The handler can reject the value
+	_, err = ParseAndValidate(token, &ExtendedClaims{}, testSecret, WithCriticalHeader("exp-ext", func(interface{}) error {
+		return errors.New("unexpected value")
+	}))
+	assert.True(t, ErrCriticalHeader.IsType(err))
+	assert.Contains(t, err.Error(), "unexpected value")
+
+	// Tokens without "crit" do not require the extension
+	token = signRaw(t, `{"alg":"HS256","typ":"JWT"}`, critClaims)
+	_, err = ParseAndValidate(token, &ExtendedClaims{}, testSecret, handler)
+	assert.Nil(t, err)
+}
+
+func TestCriticalHeaderInvalid(t *testing.T) {
+	handler := WithCriticalHeader("exp-ext", nil)
+	for header, msg := range map[string]string{
+		// RFC 7515 section 4.1.11: the array must not be empty
+		`{"alg":"HS256","typ":"JWT","crit":[]}`:                        "must not be empty",
+		`{"alg":"HS256","typ":"JWT","crit":"exp-ext","exp-ext":1}`:     "expected a non empty array",
+		`{"alg":"HS256","typ":"JWT","crit":["exp-ext",1],"exp-ext":1}`: "expected a non empty array",
+		`{"alg":"HS256","typ":"JWT","crit":null}`:                      "expected a non empty array",
+		// Listed names must be present in the header
+		`{"alg":"HS256","typ":"JWT","crit":["exp-ext"]}`: "missing from the header",
+		// Registered names must not be listed
+		`{"alg":"HS256","typ":"JWT","crit":["alg"]}`:                           "registered header",
+		`{"alg":"HS256","typ":"JWT","kid":"k1","crit":["kid"]}`:                "registered header",
+		`{"alg":"HS256","typ":"JWT","crit":["crit"]}`:                          "registered header",
+		`{"alg":"HS256","typ":"JWT","crit":["exp-ext","exp-ext"],"exp-ext":1}`: "more than once",
+	} {
+		_, err := ParseAndValidate(signRaw(t, header, critClaims), &ExtendedClaims{}, testSecret, handler, WithCriticalHeader("kid", nil))
+		assert.True(t, ErrCriticalHeader.IsType(err), header)
+		if err != nil {
+			assert.Contains(t, err.Error(), msg, header)
+		}
+	}
+}
@@ -0,0 +1,33 @@
+package jwt
+
+import "strings"
+
+// verifyType ensures the "typ" header is one of the accepted media types
+func verifyType(h TokenHeader, accepted []string) error {
+	_, present := h[headerTypeKey]
+	typ := normalizeMediaType(h.Type())
+	for _, a := range accepted {
+		if a == "" {
+			if !present {
+				return nil
+			}
+			continue
+		}
+		if present && typ == normalizeMediaType(a) {
+			return nil
+		}
+	}
+	if !present {
+		return newError(ErrTokenType, "the token has no 'typ' header")
+	}
+	return newErrorf(ErrTokenType, "unknown token type '%s'", h.Type())
+}
+
+// normalizeMediaType lower cases the media type and removes the optional "application/" prefix
+func normalizeMediaType(typ string) string {
+	typ = strings.ToLower(typ)
+	if strings.HasPrefix(typ, "application/") && !strings.Contains(typ[len("application/"):], "/") {
+		typ = typ[len("application/"):]
+	}
+	return typ
+}
@@ -0,0 +1,80 @@
+package jwt
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAcceptedTypes(t *testing.T) {
+	for typ, accepted := range map[string][]string{
+		"JWT":                     nil,
+		"jwt":                     nil,
+		"application/jwt":         nil,
+		"Application/JWT":         nil,
+		"at+jwt":                  {MediaTypeAccessToken},
+		"AT+JWT":                  {MediaTypeAccessToken},
+		"application/at+jwt":      {MediaTypeAccessToken},
+		"logout+jwt":              {MediaTypeAccessToken, MediaTypeLogoutToken},
+		"application/logout+jwt":  {"Application/Logout+JWT"},
+		"application/example+jwt": {"example+jwt"},
+	} {
+		token := signRaw(t, `{"alg":"HS256","typ":"`+typ+`"}`, `{"sub":"alice"}`)
+		options := []ParseOption{}
+		if accepted != nil {
+			options = append(options, WithAcceptedTypes(accepted...))
+		}
+		_, err := ParseAndValidate(token, &ExtendedClaims{}, testSecret, options...)
+		assert.Nil(t, err, typ)
+	}
+}
+
+func TestRejectedTypes(t *testing.T) {
+	for typ, accepted := range map[string][]string{
+		"at+jwt":                 nil,
+		"JOSE":                   nil,
+		"application/jwt/extra":  nil,
+		"text/jwt":               nil,
+		"jwt":                    {MediaTypeAccessToken},
+		"application/logout+jwt": {MediaTypeAccessToken},
+		"":                       nil,
+	} {
+		token := signRaw(t, `{"alg":"HS256","typ":"`+typ+`"}`, `{"sub":"alice"}`)
+		options := []ParseOption{}
+		if accepted != nil {
+			options = append(options, WithAcceptedTypes(accepted...))
+		}
+		_, err := ParseAndValidate(token, &ExtendedClaims{}, testSecret, options...)
+		assert.True(t, ErrTokenType.IsType(err), typ)
+	}
+
+	// A missing "typ" is only accepted if allowed explicitly
+	token := signRaw(t, `{"alg":"HS256"}`, `{"sub":"alice"}`)
+	_, err := ParseAndValidate(token, &ExtendedClaims{}, testSecret)
+	assert.True(t, ErrTokenType.IsType(err))
+	_, err = ParseAndValidate(token, &ExtendedClaims{}, testSecret, WithAcceptedTypes(MediaTypeJWT, ""))
+	assert.Nil(t, err)
+}
+
+func TestWithType(t *testing.T) {
+	data := &TokenData{Token: testClaims()}
+	token, err := data.Sign(testSecret, WithType(MediaTypeAccessToken))
+	assert.Nil(t, err)
+
+	parsed, err := ParseAndValidate(token, &ExtendedClaims{}, testSecret, WithAcceptedTypes(MediaTypeAccessToken))
+	assert.Nil(t, err)
+	assert.Equal(t, MediaTypeAccessToken, parsed.Header.Type())
+
+	// Access tokens cannot be substituted for other tokens
+	_, err = ParseAndValidate(token, &ExtendedClaims{}, testSecret)
+	assert.True(t, ErrTokenType.IsType(err))
+	_, err = ParseAndValidate(token, &ExtendedClaims{}, testSecret, WithAcceptedTypes(MediaTypeLogoutToken))
+	assert.True(t, ErrTokenType.IsType(err))
+
+	// The default type is "JWT"
+	token, err = Sign(testClaims(), testSecret)
+	assert.Nil(t, err)
+	parsed, err = ParseAndValidate(token, &ExtendedClaims{}, testSecret)
+	assert.Nil(t, err)
+	assert.Equal(t, MediaTypeJWT, parsed.Header.Type())
+}
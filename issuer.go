@@ -0,0 +1,148 @@
+package jwt
+
+import (
+	"time"
+
+	"github.com/jucardi/go-jwt/signing"
+)
+
+// DefaultTTLs are the lifetimes of the tokens issued by an Issuer for each TokenType, unless set in
+// `Issuer.TTL`
+var DefaultTTLs = map[TokenType]time.Duration{
+	TokenTypeAuth:    24 * time.Hour,
+	TokenTypeAccess:  defaultAccessTTL,
+	TokenTypeRefresh: defaultRefreshTTL,
+}
+
+// defaultIssuerTTL is the lifetime of the tokens of types without a configured TTL
+const defaultIssuerTTL = time.Hour
+
+// SigningKey is a key used by an Issuer to sign tokens
+type SigningKey struct {
+	// Key is the private key
+	Key interface{}
+	// Algorithm is the signing algorithm, the default one for the key if empty
+	Algorithm signing.Algorithm
+	// KeyID is set as the "kid" header of the tokens if not empty
+	KeyID string
+}
+
+// Issuer mints, signs and grants tokens with consistent claims, so every service issues tokens
+// the same way. It is safe for concurrent use as long as it is not modified.
+type Issuer struct {
+	// Name is set as the "iss" claim of the tokens
+	Name string
+	// Audience is set as the "aud" claim of the tokens if not empty
+	Audience string
+	// Key is the key used to sign the tokens
+	Key SigningKey
+	// KeysByType overrides the key used to sign the tokens of specific types
+	KeysByType map[TokenType]SigningKey
+	// TTL overrides the lifetime of the tokens of specific types, see `DefaultTTLs`
+	TTL map[TokenType]time.Duration
+	// SignOptions are the options used to sign every token, e.g. `WithType`
+	SignOptions []SignOption
+}
+
+// NewIssuer creates a new Issuer
+//
+//   {name}       - The issuer name, set as the "iss" claim of the tokens
+//   {privateKey} - The key used to sign the tokens
+//   {algorithm}  - (optional) The signing algorithm, the default one for the key if not provided
+//
+func NewIssuer(name string, privateKey interface{}, algorithm ...signing.Algorithm) *Issuer {
+	ret := &Issuer{Name: name, Key: SigningKey{Key: privateKey}}
+	if len(algorithm) > 0 {
+		ret.Key.Algorithm = algorithm[0]
+	}
+	return ret
+}
+
+// Issue mints a token of the provided type from the claims, signs it and returns its Grant
+//
+//   {tokenType} - The type of the token
+//   {claims}    - The claims of the token, such as the subject and scope. The registered time
+//                 claims, "jti" and "type" are set by the issuer, and "iss" and "aud" if configured.
+//
+func (i *Issuer) Issue(tokenType TokenType, claims *ExtendedClaims) (*Grant, error) {
+	token, minted, err := i.IssueToken(tokenType, claims)
+	if err != nil {
+		return nil, err
+	}
+	return NewGrant(token, minted), nil
+}
+
+// IssuePair issues an authentication token and an access token from the same claims
+//
+//   {claims} - The claims of the tokens, see `Issue`
+//
+func (i *Issuer) IssuePair(claims *ExtendedClaims) (auth, access *Grant, err error) {
+	if auth, err = i.Issue(TokenTypeAuth, claims); err != nil {
+		return nil, nil, err
+	}
+	if access, err = i.Issue(TokenTypeAccess, claims); err != nil {
+		return nil, nil, err
+	}
+	return auth, access, nil
+}
+
+// IssueToken mints a token of the provided type from the claims and signs it, returning the token
+// string with the minted claims
+//
+//   {tokenType} - The type of the token
+//   {claims}    - The claims of the token, see `Issue`
+//
+func (i *Issuer) IssueToken(tokenType TokenType, claims *ExtendedClaims) (string, *ExtendedClaims, error) {
+	if claims == nil {
+		return "", nil, newError(ErrNilToken, "failed to issue token, claims are nil")
+	}
+	minted := mintClaims(claims, tokenType, i.Name, i.Audience, i.ttl(tokenType), time.Now())
+
+	key := i.signingKey(tokenType)
+	data := &TokenData{Token: minted, Algorithm: key.Algorithm, Header: TokenHeader{}}
+	if key.KeyID != "" {
+		data.Header.SetKeyID(key.KeyID)
+	}
+	token, err := data.Sign(key.Key, i.SignOptions...)
+	if err != nil {
+		return "", nil, err
+	}
+	return token, minted, nil
+}
+
+// signingKey returns the key used to sign the tokens of the provided type
+func (i *Issuer) signingKey(tokenType TokenType) SigningKey {
+	if k, ok := i.KeysByType[tokenType]; ok {
+		return k
+	}
+	return i.Key
+}
+
+func (i *Issuer) ttl(tokenType TokenType) time.Duration {
+	if ttl, ok := i.TTL[tokenType]; ok && ttl > 0 {
+		return ttl
+	}
+	if ttl, ok := DefaultTTLs[tokenType]; ok && ttl > 0 {
+		return ttl
+	}
+	return defaultIssuerTTL
+}
+
+// mintClaims copies the claims and sets the registered claims of a new token. Times are truncated
+// to seconds, since not every consumer accepts fractional NumericDate values.
+func mintClaims(claims *ExtendedClaims, tokenType TokenType, issuer, audience string, ttl time.Duration, now time.Time) *ExtendedClaims {
+	now = now.Truncate(time.Second)
+	ret := *claims
+	ret.Type = tokenType
+	ret.Id = newTokenID()
+	if issuer != "" {
+		ret.Issuer = issuer
+	}
+	if audience != "" {
+		ret.Audience = audience
+	}
+	ret.IssuedAt = NewNumericDate(now)
+	ret.NotBefore = nil
+	ret.ExpiresAt = NewNumericDate(now.Add(ttl))
+	return &ret
+}
@@ -0,0 +1,112 @@
+package jwt
+
+import (
+	"crypto/rsa"
+	"testing"
+	"time"
+
+	"github.com/jucardi/go-jwt/keys"
+	"github.com/jucardi/go-jwt/signing"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIssuerGrant(t *testing.T) {
+	issuer := NewIssuer("issuer", testSecret)
+	issuer.Audience = "audience"
+	claims := &ExtendedClaims{Subject: "alice", Scope: "read", Fields: map[string]interface{}{"tenant": "acme"}}
+
+	before := time.Now().Truncate(time.Second)
+	grant, err := issuer.Issue(TokenTypeAccess, claims)
+	assert.Nil(t, err)
+	assert.Equal(t, TokenTypeAccess, grant.TokenType())
+	assert.Empty(t, claims.Id, "the provided claims are not modified")
+
+	parsed := &ExtendedClaims{}
+	data, err := ParseAndValidate(grant.Token, parsed, testSecret)
+	assert.Nil(t, err)
+	assert.Equal(t, signing.AlgorithmHS256, data.Header.Algorithm())
+	assert.Empty(t, data.Header.KeyID())
+	assert.Equal(t, "issuer", parsed.Issuer)
+	assert.Equal(t, "audience", parsed.Audience)
+	assert.Equal(t, "alice", parsed.Subject)
+	assert.Equal(t, "read", parsed.Scope)
+	assert.Equal(t, "acme", parsed.Fields["tenant"])
+	assert.Equal(t, TokenTypeAccess, parsed.Type)
+	assert.NotEmpty(t, parsed.Id)
+	assert.Nil(t, parsed.NotBefore)
+	assert.False(t, parsed.IssuedAt.Before(before))
+
+	// The grant expiration matches the "exp" claim
+	exp, err := grant.ExpiresAt()
+	assert.Nil(t, err)
+	assert.True(t, exp.Equal(parsed.ExpiresAt.Time))
+	assert.Equal(t, parsed.ExpiresAt.UTC().Format(time.RFC3339), grant.Exp)
+
+	_, err = issuer.Issue(TokenTypeAccess, nil)
+	assert.True(t, ErrNilToken.IsType(err))
+}
+
+func TestIssuerTTL(t *testing.T) {
+	issuer := NewIssuer("issuer", testSecret)
+	issuer.TTL = map[TokenType]time.Duration{TokenTypeAccess: 5 * time.Minute}
+
+	for tokenType, ttl := range map[TokenType]time.Duration{
+		TokenTypeAuth:        DefaultTTLs[TokenTypeAuth],
+		TokenTypeAccess:      5 * time.Minute,
+		TokenTypeRefresh:     DefaultTTLs[TokenTypeRefresh],
+		TokenType("session"): defaultIssuerTTL,
+	} {
+		_, minted, err := issuer.IssueToken(tokenType, &ExtendedClaims{Subject: "alice"})
+		assert.Nil(t, err)
+		assert.Equal(t, ttl, minted.ExpiresAt.Sub(minted.IssuedAt.Time), string(tokenType))
+	}
+}
+
+func TestIssuerKeysByType(t *testing.T) {
+	k, err := keys.LoadPrivateKey("test_assets/rsa.priv.pkcs1")
+	assert.Nil(t, err)
+	rsaKey := k.(*rsa.PrivateKey)
+
+	issuer := NewIssuer("issuer", testSecret)
+	issuer.Key.KeyID = "hs-1"
+	issuer.KeysByType = map[TokenType]SigningKey{
+		TokenTypeRefresh: {Key: rsaKey, Algorithm: signing.AlgorithmRS384, KeyID: "rsa-1"},
+	}
+
+	token, _, err := issuer.IssueToken(TokenTypeAccess, &ExtendedClaims{Subject: "alice"})
+	assert.Nil(t, err)
+	data, err := ParseAndValidate(token, &ExtendedClaims{}, testSecret)
+	assert.Nil(t, err)
+	assert.Equal(t, "hs-1", data.Header.KeyID())
+	assert.Equal(t, signing.AlgorithmHS256, data.Header.Algorithm())
+
+	token, _, err = issuer.IssueToken(TokenTypeRefresh, &ExtendedClaims{Subject: "alice"})
+	assert.Nil(t, err)
+	data, err = ParseAndValidate(token, &ExtendedClaims{}, &rsaKey.PublicKey)
+	assert.Nil(t, err)
+	assert.Equal(t, "rsa-1", data.Header.KeyID())
+	assert.Equal(t, signing.AlgorithmRS384, data.Header.Algorithm())
+
+	_, err = ParseAndValidate(token, &ExtendedClaims{}, testSecret)
+	assert.NotNil(t, err)
+}
+
+func TestIssuerSignOptions(t *testing.T) {
+	issuer := NewIssuer("issuer", testSecret)
+	issuer.SignOptions = []SignOption{WithType(MediaTypeAccessToken)}
+
+	auth, access, err := issuer.IssuePair(&ExtendedClaims{Subject: "alice"})
+	assert.Nil(t, err)
+	assert.Equal(t, TokenTypeAuth, auth.TokenType())
+	assert.Equal(t, TokenTypeAccess, access.TokenType())
+
+	for _, grant := range []*Grant{auth, access} {
+		data, err := ParseAndValidate(grant.Token, &ExtendedClaims{}, testSecret, WithAcceptedTypes(MediaTypeAccessToken))
+		assert.Nil(t, err)
+		assert.Equal(t, MediaTypeAccessToken, data.Header.Type())
+
+		// The default accepted type is "JWT"
+		_, err = ParseAndValidate(grant.Token, &ExtendedClaims{}, testSecret)
+		assert.NotNil(t, err)
+	}
+}
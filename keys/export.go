@@ -0,0 +1,48 @@
+package keys
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+)
+
+// ExportPEM encodes the provided key as PEM. Private keys are encoded as PKCS#8 ("PRIVATE KEY")
+// and public keys as PKIX ("PUBLIC KEY"), both of which can be loaded back with `Parse`.
+//
+//   {key} - The *rsa.PrivateKey, *ecdsa.PrivateKey, ed25519.PrivateKey or their public counterparts
+//
+func ExportPEM(key interface{}) ([]byte, error) {
+	block := &pem.Block{}
+	switch key.(type) {
+	case *rsa.PrivateKey, *ecdsa.PrivateKey, ed25519.PrivateKey:
+		der, err := x509.MarshalPKCS8PrivateKey(key)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal private key, %s", err.Error())
+		}
+		block.Type, block.Bytes = pemTypePrivate, der
+	case *rsa.PublicKey, *ecdsa.PublicKey, ed25519.PublicKey:
+		der, err := x509.MarshalPKIXPublicKey(key)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal public key, %s", err.Error())
+		}
+		block.Type, block.Bytes = pemTypePublic, der
+	default:
+		return nil, fmt.Errorf("unable to export key to PEM, unsupported key type %T", key)
+	}
+	return pem.EncodeToMemory(block), nil
+}
+
+// ExportPublicPEM encodes the public half of the provided key as PKIX PEM ("PUBLIC KEY")
+//
+//   {key} - The private or public key
+//
+func ExportPublicPEM(key interface{}) ([]byte, error) {
+	pub, err := PublicKey(key)
+	if err != nil {
+		return nil, err
+	}
+	return ExportPEM(pub)
+}
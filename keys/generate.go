@@ -0,0 +1,66 @@
+package keys
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"fmt"
+
+	"github.com/jucardi/go-jwt/signing"
+)
+
+const (
+	// MinRSABits is the minimum RSA modulus size in bits accepted by `Generate`
+	MinRSABits = 2048
+)
+
+// Generate generates a new random key suitable for the provided signing algorithm.
+//
+//   - HS256, HS384, HS512: a []byte secret with the length of the hash output (32, 48, 64 bytes)
+//   - RS256, RS384, RS512: a *rsa.PrivateKey, 2048 bits by default
+//   - ES256, ES384, ES512: a *ecdsa.PrivateKey on the P-256, P-384 and P-521 curves respectively
+//   - EdDSA:               an ed25519.PrivateKey
+//
+//   {alg}  - The signing algorithm the key will be used with
+//   {bits} - (optional) The RSA modulus size in bits. Must be at least `MinRSABits`. Ignored for
+//            other algorithms.
+//
+func Generate(alg signing.Algorithm, bits ...int) (interface{}, error) {
+	switch alg {
+	case signing.AlgorithmHS256:
+		return generateSecret(32)
+	case signing.AlgorithmHS384:
+		return generateSecret(48)
+	case signing.AlgorithmHS512:
+		return generateSecret(64)
+	case signing.AlgorithmRS256, signing.AlgorithmRS384, signing.AlgorithmRS512:
+		size := MinRSABits
+		if len(bits) > 0 && bits[0] > 0 {
+			size = bits[0]
+		}
+		if size < MinRSABits {
+			return nil, fmt.Errorf("RSA key size must be at least %d bits, got %d", MinRSABits, size)
+		}
+		return rsa.GenerateKey(rand.Reader, size)
+	case signing.AlgorithmES256:
+		return ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	case signing.AlgorithmES384:
+		return ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	case signing.AlgorithmES512:
+		return ecdsa.GenerateKey(elliptic.P521(), rand.Reader)
+	case signing.AlgorithmEdDSA:
+		_, key, err := ed25519.GenerateKey(rand.Reader)
+		return key, err
+	}
+	return nil, fmt.Errorf("unable to generate key, algorithm '%s' not supported", alg)
+}
+
+func generateSecret(size int) ([]byte, error) {
+	ret := make([]byte, size)
+	if _, err := rand.Read(ret); err != nil {
+		return nil, fmt.Errorf("failed to generate secret, %s", err.Error())
+	}
+	return ret, nil
+}
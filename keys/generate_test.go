@@ -0,0 +1,68 @@
+package keys
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"testing"
+
+	"github.com/jucardi/go-jwt/encoding"
+	"github.com/jucardi/go-jwt/signing"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerate(t *testing.T) {
+	for _, alg := range []signing.Algorithm{
+		signing.AlgorithmHS256, signing.AlgorithmHS384, signing.AlgorithmHS512,
+		signing.AlgorithmRS256, signing.AlgorithmES256, signing.AlgorithmES384,
+		signing.AlgorithmES512, signing.AlgorithmEdDSA,
+	} {
+		key, err := Generate(alg)
+		assert.Nil(t, err, alg.String())
+
+		// The generated key is suitable for the algorithm and meets the key policy
+		public := key
+		if _, ok := key.([]byte); !ok {
+			public, err = PublicKey(key)
+			assert.Nil(t, err, alg.String())
+			assert.Equal(t, alg, signing.DefaultFromKey(key), alg.String())
+		}
+		signature, err := alg.Signer().Sign("payload", key)
+		assert.Nil(t, err, alg.String())
+		raw, err := encoding.DecodeSegment(signature)
+		assert.Nil(t, err)
+		assert.Nil(t, alg.Signer().Verify([]byte("payload"), raw, public), alg.String())
+	}
+}
+
+func TestGenerateKeyTypes(t *testing.T) {
+	for alg, size := range map[signing.Algorithm]int{signing.AlgorithmHS256: 32, signing.AlgorithmHS384: 48, signing.AlgorithmHS512: 64} {
+		key, err := Generate(alg)
+		assert.Nil(t, err)
+		assert.Len(t, key, size, alg.String())
+		other, _ := Generate(alg)
+		assert.NotEqual(t, key, other, alg.String())
+	}
+
+	key, err := Generate(signing.AlgorithmRS512)
+	assert.Nil(t, err)
+	assert.Equal(t, MinRSABits, key.(*rsa.PrivateKey).N.BitLen())
+
+	key, err = Generate(signing.AlgorithmES384)
+	assert.Nil(t, err)
+	assert.Equal(t, "P-384", key.(*ecdsa.PrivateKey).Curve.Params().Name)
+
+	key, err = Generate(signing.AlgorithmEdDSA)
+	assert.Nil(t, err)
+	assert.Len(t, key.(ed25519.PrivateKey), ed25519.PrivateKeySize)
+}
+
+func TestGenerateErrors(t *testing.T) {
+	_, err := Generate(signing.AlgorithmRS256, 1024)
+	assert.EqualError(t, err, "RSA key size must be at least 2048 bits, got 1024")
+
+	for _, alg := range []signing.Algorithm{signing.AlgorithmNone, "unknown"} {
+		_, err = Generate(alg)
+		assert.Contains(t, err.Error(), "not supported", alg.String())
+	}
+}
@@ -0,0 +1,373 @@
+package keys
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/jucardi/go-jwt/encoding"
+	"github.com/jucardi/go-jwt/signing"
+)
+
+const (
+	KeyTypeRSA = "RSA" // KeyTypeRSA is the JWK key type for RSA keys
+	KeyTypeEC  = "EC"  // KeyTypeEC is the JWK key type for elliptic curve keys
+	KeyTypeOKP = "OKP" // KeyTypeOKP is the JWK key type for octet key pairs (Ed25519)
+	KeyTypeOct = "oct" // KeyTypeOct is the JWK key type for symmetric (HMAC) keys
+
+	curveP256    = "P-256"
+	curveP384    = "P-384"
+	curveP521    = "P-521"
+	curveEd25519 = "Ed25519"
+)
+
+// JWK is the JSON Web Key representation of a key as defined in RFC 7517. All the key material
+// members are base64url encoded as required by RFC 7518.
+type JWK struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid,omitempty"`
+	Use       string `json:"use,omitempty"`
+	Algorithm string `json:"alg,omitempty"`
+
+	// EC and OKP keys
+	Curve string `json:"crv,omitempty"`
+	X     string `json:"x,omitempty"`
+	Y     string `json:"y,omitempty"`
+
+	// RSA keys
+	N  string `json:"n,omitempty"`
+	E  string `json:"e,omitempty"`
+	P  string `json:"p,omitempty"`
+	Q  string `json:"q,omitempty"`
+	DP string `json:"dp,omitempty"`
+	DQ string `json:"dq,omitempty"`
+	QI string `json:"qi,omitempty"`
+
+	// Private exponent (RSA), private scalar (EC) or seed (OKP)
+	D string `json:"d,omitempty"`
+
+	// Symmetric keys
+	K string `json:"k,omitempty"`
+}
+
+// ExportJWK converts the provided key into its JWK representation.
+//
+//   {key} - The *rsa.PrivateKey, *ecdsa.PrivateKey, ed25519.PrivateKey, their public counterparts
+//           or a []byte HMAC secret
+//   {alg} - (optional) The signing algorithm to set as the "alg" member of the JWK
+//
+func ExportJWK(key interface{}, alg ...signing.Algorithm) (*JWK, error) {
+	ret := &JWK{}
+	if len(alg) > 0 {
+		ret.Algorithm = alg[0].String()
+	}
+
+	switch k := key.(type) {
+	case *rsa.PrivateKey:
+		if len(k.Primes) != 2 {
+			return nil, errors.New("unable to export RSA key to JWK, multi-prime keys are not supported")
+		}
+		if err := setRSAPrivate(ret, k); err != nil {
+			return nil, err
+		}
+	case *rsa.PublicKey:
+		setRSAPublic(ret, k)
+	case *ecdsa.PrivateKey:
+		if err := setECPublic(ret, &k.PublicKey); err != nil {
+			return nil, err
+		}
+		ret.D = encodeInt(k.D, curveSize(k.Curve))
+	case *ecdsa.PublicKey:
+		if err := setECPublic(ret, k); err != nil {
+			return nil, err
+		}
+	case ed25519.PrivateKey:
+		ret.KeyType, ret.Curve = KeyTypeOKP, curveEd25519
+		ret.X = encoding.EncodeSegment(k.Public().(ed25519.PublicKey))
+		ret.D = encoding.EncodeSegment(k.Seed())
+	case ed25519.PublicKey:
+		ret.KeyType, ret.Curve = KeyTypeOKP, curveEd25519
+		ret.X = encoding.EncodeSegment(k)
+	case []byte:
+		ret.KeyType = KeyTypeOct
+		ret.K = encoding.EncodeSegment(k)
+	default:
+		return nil, fmt.Errorf("unable to export key to JWK, unsupported key type %T", key)
+	}
+	return ret, nil
+}
+
+// ParseJWK parses a JSON encoded JWK and returns the key it represents. See `JWK.Key`
+//
+//   {data} - The JSON encoded JWK
+//
+func ParseJWK(data []byte) (interface{}, error) {
+	jwk := &JWK{}
+	if err := json.Unmarshal(data, jwk); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal JWK, %s", err.Error())
+	}
+	return jwk.Key()
+}
+
+// IsPrivate indicates whether the JWK contains private key material
+func (j *JWK) IsPrivate() bool {
+	return j != nil && (j.D != "" || j.K != "")
+}
+
+// Public returns a copy of this JWK with all the private key members removed. Symmetric keys do
+// not have a public half, so an error is returned for "oct" keys.
+func (j *JWK) Public() (*JWK, error) {
+	if j == nil {
+		return nil, errors.New("JWK is nil")
+	}
+	if j.KeyType == KeyTypeOct {
+		return nil, errors.New("symmetric keys do not have a public half")
+	}
+	ret := *j
+	ret.D, ret.P, ret.Q, ret.DP, ret.DQ, ret.QI = "", "", "", "", "", ""
+	return &ret, nil
+}
+
+// Key returns the key represented by this JWK. Private JWKs are returned as *rsa.PrivateKey,
+// *ecdsa.PrivateKey or ed25519.PrivateKey, public JWKs as *rsa.PublicKey, *ecdsa.PublicKey or
+// ed25519.PublicKey, and symmetric keys as []byte.
+func (j *JWK) Key() (interface{}, error) {
+	if j == nil {
+		return nil, errors.New("JWK is nil")
+	}
+	switch j.KeyType {
+	case KeyTypeRSA:
+		return j.rsaKey()
+	case KeyTypeEC:
+		return j.ecKey()
+	case KeyTypeOKP:
+		return j.okpKey()
+	case KeyTypeOct:
+		k, err := decodeMember("k", j.K)
+		if err != nil {
+			return nil, err
+		}
+		if len(k) == 0 {
+			return nil, errors.New("invalid JWK, missing 'k'")
+		}
+		return k, nil
+	}
+	return nil, fmt.Errorf("unsupported JWK key type '%s'", j.KeyType)
+}
+
+// MarshalKey is a shorthand for `ExportJWK` followed by `json.Marshal`
+//
+//   {key} - The key to export
+//   {alg} - (optional) The signing algorithm to set as the "alg" member of the JWK
+//
+func MarshalKey(key interface{}, alg ...signing.Algorithm) ([]byte, error) {
+	jwk, err := ExportJWK(key, alg...)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(jwk)
+}
+
+func (j *JWK) rsaKey() (interface{}, error) {
+	n, err := decodeBigInt("n", j.N)
+	if err != nil {
+		return nil, err
+	}
+	e, err := decodeBigInt("e", j.E)
+	if err != nil {
+		return nil, err
+	}
+	if n == nil || e == nil {
+		return nil, errors.New("invalid JWK, RSA keys require 'n' and 'e'")
+	}
+	if !e.IsInt64() || e.Int64() < 2 || e.Int64() > 1<<31-1 {
+		return nil, errors.New("invalid JWK, RSA exponent out of range")
+	}
+	pub := &rsa.PublicKey{N: n, E: int(e.Int64())}
+	if j.D == "" {
+		return pub, nil
+	}
+
+	d, err := decodeBigInt("d", j.D)
+	if err != nil {
+		return nil, err
+	}
+	p, err := decodeBigInt("p", j.P)
+	if err != nil {
+		return nil, err
+	}
+	q, err := decodeBigInt("q", j.Q)
+	if err != nil {
+		return nil, err
+	}
+	if p == nil || q == nil {
+		return nil, errors.New("invalid JWK, RSA private keys require 'p' and 'q'")
+	}
+	key := &rsa.PrivateKey{PublicKey: *pub, D: d, Primes: []*big.Int{p, q}}
+	if err := key.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid JWK, %s", err.Error())
+	}
+	key.Precompute()
+	return key, nil
+}
+
+func (j *JWK) ecKey() (interface{}, error) {
+	var curve elliptic.Curve
+	switch j.Curve {
+	case curveP256:
+		curve = elliptic.P256()
+	case curveP384:
+		curve = elliptic.P384()
+	case curveP521:
+		curve = elliptic.P521()
+	default:
+		return nil, fmt.Errorf("unsupported JWK curve '%s'", j.Curve)
+	}
+	size := curveSize(curve)
+
+	x, err := decodeMember("x", j.X)
+	if err != nil {
+		return nil, err
+	}
+	y, err := decodeMember("y", j.Y)
+	if err != nil {
+		return nil, err
+	}
+	if len(x) != size || len(y) != size {
+		return nil, fmt.Errorf("invalid JWK, 'x' and 'y' must be %d bytes long for curve %s", size, j.Curve)
+	}
+	pub := &ecdsa.PublicKey{Curve: curve, X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}
+	if !curve.IsOnCurve(pub.X, pub.Y) {
+		return nil, errors.New("invalid JWK, point is not on the curve")
+	}
+	if j.D == "" {
+		return pub, nil
+	}
+
+	d, err := decodeMember("d", j.D)
+	if err != nil {
+		return nil, err
+	}
+	if len(d) != size {
+		return nil, fmt.Errorf("invalid JWK, 'd' must be %d bytes long for curve %s", size, j.Curve)
+	}
+	key := &ecdsa.PrivateKey{PublicKey: *pub, D: new(big.Int).SetBytes(d)}
+	if cx, cy := curve.ScalarBaseMult(d); cx.Cmp(pub.X) != 0 || cy.Cmp(pub.Y) != 0 {
+		return nil, errors.New("invalid JWK, private key does not match the public key")
+	}
+	return key, nil
+}
+
+func (j *JWK) okpKey() (interface{}, error) {
+	if j.Curve != curveEd25519 {
+		return nil, fmt.Errorf("unsupported JWK curve '%s'", j.Curve)
+	}
+	x, err := decodeMember("x", j.X)
+	if err != nil {
+		return nil, err
+	}
+	if len(x) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("invalid JWK, 'x' must be %d bytes long", ed25519.PublicKeySize)
+	}
+	if j.D == "" {
+		return ed25519.PublicKey(x), nil
+	}
+
+	d, err := decodeMember("d", j.D)
+	if err != nil {
+		return nil, err
+	}
+	if len(d) != ed25519.SeedSize {
+		return nil, fmt.Errorf("invalid JWK, 'd' must be %d bytes long", ed25519.SeedSize)
+	}
+	key := ed25519.NewKeyFromSeed(d)
+	if !key.Public().(ed25519.PublicKey).Equal(ed25519.PublicKey(x)) {
+		return nil, errors.New("invalid JWK, private key does not match the public key")
+	}
+	return key, nil
+}
+
+func setRSAPublic(jwk *JWK, key *rsa.PublicKey) {
+	jwk.KeyType = KeyTypeRSA
+	jwk.N = encodeInt(key.N, 0)
+	jwk.E = encodeInt(big.NewInt(int64(key.E)), 0)
+}
+
+// setRSAPrivate sets the members of the RSA private key. The CRT values are computed instead of
+// calling `Precompute`, so the caller's key is not modified.
+func setRSAPrivate(jwk *JWK, key *rsa.PrivateKey) error {
+	p, q := key.Primes[0], key.Primes[1]
+	if key.D == nil || p == nil || q == nil {
+		return errors.New("unable to export RSA key to JWK, the private exponent or primes are missing")
+	}
+	one := big.NewInt(1)
+	qi := new(big.Int).ModInverse(q, p)
+	if qi == nil {
+		return errors.New("unable to export RSA key to JWK, the primes are not coprime")
+	}
+	setRSAPublic(jwk, &key.PublicKey)
+	jwk.D = encodeInt(key.D, 0)
+	jwk.P = encodeInt(p, 0)
+	jwk.Q = encodeInt(q, 0)
+	jwk.DP = encodeInt(new(big.Int).Mod(key.D, new(big.Int).Sub(p, one)), 0)
+	jwk.DQ = encodeInt(new(big.Int).Mod(key.D, new(big.Int).Sub(q, one)), 0)
+	jwk.QI = encodeInt(qi, 0)
+	return nil
+}
+
+func setECPublic(jwk *JWK, key *ecdsa.PublicKey) error {
+	switch key.Curve {
+	case elliptic.P256():
+		jwk.Curve = curveP256
+	case elliptic.P384():
+		jwk.Curve = curveP384
+	case elliptic.P521():
+		jwk.Curve = curveP521
+	default:
+		return errors.New("unable to export EC key to JWK, unsupported curve")
+	}
+	size := curveSize(key.Curve)
+	jwk.KeyType = KeyTypeEC
+	jwk.X = encodeInt(key.X, size)
+	jwk.Y = encodeInt(key.Y, size)
+	return nil
+}
+
+func curveSize(curve elliptic.Curve) int {
+	return (curve.Params().BitSize + 7) / 8
+}
+
+// encodeInt encodes the integer as big-endian base64url, left padded with zeros to `size` bytes
+func encodeInt(i *big.Int, size int) string {
+	b := i.Bytes()
+	if len(b) < size {
+		padded := make([]byte, size)
+		copy(padded[size-len(b):], b)
+		b = padded
+	}
+	return encoding.EncodeSegment(b)
+}
+
+func decodeMember(name, value string) ([]byte, error) {
+	ret, err := encoding.DecodeSegment(value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid JWK, failed to decode '%s', %s", name, err.Error())
+	}
+	return ret, nil
+}
+
+func decodeBigInt(name, value string) (*big.Int, error) {
+	if value == "" {
+		return nil, nil
+	}
+	b, err := decodeMember(name, value)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(b), nil
+}
@@ -0,0 +1,103 @@
+package keys
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"testing"
+
+	"github.com/jucardi/go-jwt/signing"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJWKRoundTrip(t *testing.T) {
+	rsaKey, err := ParsePrivateKey(asset(t, "rsa.priv.pkcs1"))
+	assert.Nil(t, err)
+	p256, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	p384, _ := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	p521, _ := ecdsa.GenerateKey(elliptic.P521(), rand.Reader)
+	_, edKey, _ := ed25519.GenerateKey(rand.Reader)
+
+	for name, key := range map[string]crypto.Signer{
+		"RSA":     rsaKey.(*rsa.PrivateKey),
+		"P-256":   p256,
+		"P-384":   p384,
+		"P-521":   p521,
+		"Ed25519": edKey,
+	} {
+		data, err := MarshalKey(key)
+		assert.Nil(t, err, name)
+		parsed, err := ParseJWK(data)
+		assert.Nil(t, err, name)
+		assert.True(t, parsed.(interface{ Equal(crypto.PrivateKey) bool }).Equal(key), name)
+
+		// The public half round trips on its own
+		data, err = MarshalKey(key.Public())
+		assert.Nil(t, err, name)
+		parsed, err = ParseJWK(data)
+		assert.Nil(t, err, name)
+		assert.True(t, parsed.(interface{ Equal(crypto.PublicKey) bool }).Equal(key.Public()), name)
+
+		jwk, err := ExportJWK(key)
+		assert.Nil(t, err, name)
+		assert.True(t, jwk.IsPrivate(), name)
+		public, err := jwk.Public()
+		assert.Nil(t, err, name)
+		assert.False(t, public.IsPrivate(), name)
+		parsed, err = public.Key()
+		assert.Nil(t, err, name)
+		assert.True(t, parsed.(interface{ Equal(crypto.PublicKey) bool }).Equal(key.Public()), name)
+	}
+
+	data, err := MarshalKey([]byte("secret"), signing.AlgorithmHS256)
+	assert.Nil(t, err)
+	assert.JSONEq(t, `{"kty":"oct","alg":"HS256","k":"c2VjcmV0"}`, string(data))
+	parsed, err := ParseJWK(data)
+	assert.Nil(t, err)
+	assert.Equal(t, []byte("secret"), parsed)
+}
+
+func TestExportJWKDoesNotModifyKey(t *testing.T) {
+	loaded, err := ParsePrivateKey(asset(t, "rsa.priv.pkcs1"))
+	assert.Nil(t, err)
+	expected := loaded.(*rsa.PrivateKey)
+
+	// A key without precomputed values, as built by hand or decoded elsewhere
+	key := &rsa.PrivateKey{PublicKey: expected.PublicKey, D: expected.D, Primes: expected.Primes}
+	jwk, err := ExportJWK(key)
+	assert.Nil(t, err)
+	assert.Nil(t, key.Precomputed.Dp)
+	assert.Nil(t, key.Precomputed.Dq)
+	assert.Nil(t, key.Precomputed.Qinv)
+
+	// The CRT members match the ones computed by the standard library
+	assert.Equal(t, encodeInt(expected.Precomputed.Dp, 0), jwk.DP)
+	assert.Equal(t, encodeInt(expected.Precomputed.Dq, 0), jwk.DQ)
+	assert.Equal(t, encodeInt(expected.Precomputed.Qinv, 0), jwk.QI)
+
+	data, err := json.Marshal(jwk)
+	assert.Nil(t, err)
+	parsed, err := ParseJWK(data)
+	assert.Nil(t, err)
+	assert.True(t, expected.Equal(parsed))
+}
+
+func TestJWKErrors(t *testing.T) {
+	_, err := ParseJWK([]byte(`{"kty":"RSA","n":"AQAB"}`))
+	assert.NotNil(t, err)
+	_, err = ParseJWK([]byte(`{"kty":"EC","crv":"P-256","x":"AQAB","y":"AQAB"}`))
+	assert.NotNil(t, err)
+	_, err = ParseJWK([]byte(`{"kty":"unknown"}`))
+	assert.Contains(t, err.Error(), "unsupported JWK key type")
+	_, err = ParseJWK([]byte(`not json`))
+	assert.Contains(t, err.Error(), "failed to unmarshal JWK")
+
+	_, err = (&JWK{KeyType: KeyTypeOct, K: "c2VjcmV0"}).Public()
+	assert.NotNil(t, err)
+	_, err = ExportJWK("not a key")
+	assert.Contains(t, err.Error(), "unsupported key type")
+}
@@ -0,0 +1,46 @@
+package keys
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// JWKSet is a JSON Web Key Set as defined in RFC 7517 section 5
+type JWKSet struct {
+	Keys []*JWK `json:"keys"`
+}
+
+// ParseJWKSet parses a JSON encoded JWK Set
+//
+//   {data} - The JSON encoded JWK Set
+//
+func ParseJWKSet(data []byte) (*JWKSet, error) {
+	ret := &JWKSet{}
+	if err := json.Unmarshal(data, ret); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal JWK Set, %s", err.Error())
+	}
+	return ret, nil
+}
+
+// Find returns the JWK with the provided key ID. If the key ID is empty and the set contains a
+// single key, that key is returned. Returns nil if no key matches.
+//
+//   {kid} - The key ID
+//
+func (s *JWKSet) Find(kid string) *JWK {
+	if s == nil {
+		return nil
+	}
+	if kid == "" {
+		if len(s.Keys) == 1 {
+			return s.Keys[0]
+		}
+		return nil
+	}
+	for _, k := range s.Keys {
+		if k != nil && k.KeyID == kid {
+			return k
+		}
+	}
+	return nil
+}
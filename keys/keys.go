@@ -0,0 +1,385 @@
+package keys
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+)
+
+const (
+	pemTypeRSAPrivate       = "RSA PRIVATE KEY"
+	pemTypeECPrivate        = "EC PRIVATE KEY"
+	pemTypePrivate          = "PRIVATE KEY"
+	pemTypeEncryptedPrivate = "ENCRYPTED PRIVATE KEY"
+	pemTypeRSAPublic        = "RSA PUBLIC KEY"
+	pemTypePublic           = "PUBLIC KEY"
+	pemTypeCertificate      = "CERTIFICATE"
+)
+
+// Parse parses the provided PEM or DER encoded data and returns the first key found in it. The
+// format is detected automatically. Private keys are returned as *rsa.PrivateKey, *ecdsa.PrivateKey
+// or ed25519.PrivateKey. Public keys and certificates are returned as *rsa.PublicKey,
+// *ecdsa.PublicKey or ed25519.PublicKey.
+//
+//   {data}     - The PEM or DER encoded data
+//   {password} - (optional) The password used to decrypt an encrypted PKCS#8 private key
+//
+func Parse(data []byte, password ...[]byte) (interface{}, error) {
+	blocks := decodePEM(data)
+	if len(blocks) == 0 {
+		if key, err := parsePrivateDER(data, password...); err == nil {
+			return key, nil
+		}
+		if key, err := parsePublicDER(data); err == nil {
+			return key, nil
+		}
+		return nil, errors.New("failed to parse key, unrecognized DER format")
+	}
+
+	var lastErr error
+	for _, block := range blocks {
+		var (
+			key interface{}
+			err error
+		)
+		switch block.Type {
+		case pemTypeRSAPrivate, pemTypeECPrivate, pemTypePrivate, pemTypeEncryptedPrivate:
+			key, err = parsePrivateBlock(block, password...)
+		case pemTypeRSAPublic, pemTypePublic, pemTypeCertificate:
+			key, err = parsePublicBlock(block)
+		default:
+			continue
+		}
+		if err == nil {
+			return key, nil
+		}
+		lastErr = err
+	}
+	if lastErr != nil {
+		return nil, lastErr
+	}
+	return nil, errors.New("failed to parse key, no key found in PEM data")
+}
+
+// ParsePrivateKey parses a PEM or DER encoded private key. Supported formats are PKCS#1, PKCS#8,
+// SEC1 EC and encrypted PKCS#8. The PEM block label is not trusted, the format is detected from
+// the block contents.
+//
+//   {data}     - The PEM or DER encoded private key
+//   {password} - (optional) The password used to decrypt an encrypted PKCS#8 private key
+//
+func ParsePrivateKey(data []byte, password ...[]byte) (interface{}, error) {
+	blocks := decodePEM(data)
+	if len(blocks) == 0 {
+		return parsePrivateDER(data, password...)
+	}
+	var lastErr error
+	for _, block := range blocks {
+		switch block.Type {
+		case pemTypeRSAPrivate, pemTypeECPrivate, pemTypePrivate, pemTypeEncryptedPrivate:
+			key, err := parsePrivateBlock(block, password...)
+			if err == nil {
+				return key, nil
+			}
+			lastErr = err
+		}
+	}
+	if lastErr != nil {
+		return nil, lastErr
+	}
+	return nil, errors.New("failed to parse private key, no private key found in PEM data")
+}
+
+// ParsePublicKey parses a PEM or DER encoded public key. Supported formats are PKIX, PKCS#1 and
+// X.509 certificates, in which case the certificate public key is returned. If the data only
+// contains a private key, the public half of the private key is returned.
+//
+//   {data} - The PEM or DER encoded public key, certificate or private key
+//
+func ParsePublicKey(data []byte) (interface{}, error) {
+	blocks := decodePEM(data)
+	if len(blocks) == 0 {
+		if key, err := parsePublicDER(data); err == nil {
+			return key, nil
+		}
+		if key, err := parsePrivateDER(data); err == nil {
+			return PublicKey(key)
+		}
+		return nil, errors.New("failed to parse public key, unrecognized DER format")
+	}
+
+	var lastErr error
+	for _, block := range blocks {
+		switch block.Type {
+		case pemTypeRSAPublic, pemTypePublic, pemTypeCertificate:
+			key, err := parsePublicBlock(block)
+			if err == nil {
+				return key, nil
+			}
+			lastErr = err
+		}
+	}
+	if lastErr != nil {
+		return nil, lastErr
+	}
+	if key, err := ParsePrivateKey(data); err == nil {
+		return PublicKey(key)
+	}
+	return nil, errors.New("failed to parse public key, no public key found in PEM data")
+}
+
+// ParseCertificate parses a PEM or DER encoded X.509 certificate. If the data contains a
+// certificate chain, the first certificate is returned.
+//
+//   {data} - The PEM or DER encoded certificate
+//
+func ParseCertificate(data []byte) (*x509.Certificate, error) {
+	certs, err := ParseCertificates(data)
+	if err != nil {
+		return nil, err
+	}
+	return certs[0], nil
+}
+
+// ParseCertificates parses all the PEM or DER encoded X.509 certificates contained in the
+// provided data, in the order they appear.
+//
+//   {data} - The PEM or DER encoded certificates
+//
+func ParseCertificates(data []byte) ([]*x509.Certificate, error) {
+	blocks := decodePEM(data)
+	if len(blocks) == 0 {
+		certs, err := x509.ParseCertificates(data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse certificate, %s", err.Error())
+		}
+		if len(certs) == 0 {
+			return nil, errors.New("failed to parse certificate, no certificate found")
+		}
+		return certs, nil
+	}
+
+	var ret []*x509.Certificate
+	for _, block := range blocks {
+		if block.Type != pemTypeCertificate {
+			continue
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse certificate, %s", err.Error())
+		}
+		ret = append(ret, cert)
+	}
+	if len(ret) == 0 {
+		return nil, errors.New("failed to parse certificate, no certificate found in PEM data")
+	}
+	return ret, nil
+}
+
+// Read reads all the data from the provided reader and parses it using `Parse`
+//
+//   {reader}   - The reader to read the PEM or DER encoded data from
+//   {password} - (optional) The password used to decrypt an encrypted PKCS#8 private key
+//
+func Read(reader io.Reader, password ...[]byte) (interface{}, error) {
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read key, %s", err.Error())
+	}
+	return Parse(data, password...)
+}
+
+// ReadPrivateKey reads all the data from the provided reader and parses it using `ParsePrivateKey`
+//
+//   {reader}   - The reader to read the PEM or DER encoded private key from
+//   {password} - (optional) The password used to decrypt an encrypted PKCS#8 private key
+//
+func ReadPrivateKey(reader io.Reader, password ...[]byte) (interface{}, error) {
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read private key, %s", err.Error())
+	}
+	return ParsePrivateKey(data, password...)
+}
+
+// ReadPublicKey reads all the data from the provided reader and parses it using `ParsePublicKey`
+//
+//   {reader} - The reader to read the PEM or DER encoded public key from
+//
+func ReadPublicKey(reader io.Reader) (interface{}, error) {
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read public key, %s", err.Error())
+	}
+	return ParsePublicKey(data)
+}
+
+// ReadCertificates reads all the data from the provided reader and parses it using `ParseCertificates`
+//
+//   {reader} - The reader to read the PEM or DER encoded certificates from
+//
+func ReadCertificates(reader io.Reader) ([]*x509.Certificate, error) {
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read certificates, %s", err.Error())
+	}
+	return ParseCertificates(data)
+}
+
+// Load reads the file in the provided path and parses it using `Parse`
+//
+//   {path}     - The path of the file containing the PEM or DER encoded data
+//   {password} - (optional) The password used to decrypt an encrypted PKCS#8 private key
+//
+func Load(path string, password ...[]byte) (interface{}, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read key file, %s", err.Error())
+	}
+	return Parse(data, password...)
+}
+
+// LoadPrivateKey reads the file in the provided path and parses it using `ParsePrivateKey`
+//
+//   {path}     - The path of the file containing the PEM or DER encoded private key
+//   {password} - (optional) The password used to decrypt an encrypted PKCS#8 private key
+//
+func LoadPrivateKey(path string, password ...[]byte) (interface{}, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read private key file, %s", err.Error())
+	}
+	return ParsePrivateKey(data, password...)
+}
+
+// LoadPublicKey reads the file in the provided path and parses it using `ParsePublicKey`
+//
+//   {path} - The path of the file containing the PEM or DER encoded public key
+//
+func LoadPublicKey(path string) (interface{}, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read public key file, %s", err.Error())
+	}
+	return ParsePublicKey(data)
+}
+
+// LoadCertificates reads the file in the provided path and parses it using `ParseCertificates`
+//
+//   {path} - The path of the file containing the PEM or DER encoded certificates
+//
+func LoadCertificates(path string) ([]*x509.Certificate, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read certificate file, %s", err.Error())
+	}
+	return ParseCertificates(data)
+}
+
+// PublicKey returns the public half of the provided private key. If a public key is provided,
+// it is returned as is.
+//
+//   {key} - The private or public key
+//
+func PublicKey(key interface{}) (interface{}, error) {
+	switch k := key.(type) {
+	case *rsa.PublicKey, *ecdsa.PublicKey, ed25519.PublicKey:
+		return k, nil
+	case crypto.Signer:
+		return k.Public(), nil
+	}
+	return nil, fmt.Errorf("unsupported key type %T", key)
+}
+
+func decodePEM(data []byte) []*pem.Block {
+	var ret []*pem.Block
+	for {
+		block, rest := pem.Decode(data)
+		if block == nil {
+			return ret
+		}
+		ret = append(ret, block)
+		data = rest
+	}
+}
+
+func parsePrivateBlock(block *pem.Block, password ...[]byte) (interface{}, error) {
+	if _, ok := block.Headers["DEK-Info"]; ok {
+		return nil, errors.New("failed to parse private key, legacy PEM encryption is not supported, use encrypted PKCS#8 instead")
+	}
+	return parsePrivateDER(block.Bytes, password...)
+}
+
+func parsePublicBlock(block *pem.Block) (interface{}, error) {
+	if block.Type != pemTypeCertificate {
+		return parsePublicDER(block.Bytes)
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse certificate, %s", err.Error())
+	}
+	return checkPublic(cert.PublicKey)
+}
+
+func parsePrivateDER(der []byte, password ...[]byte) (interface{}, error) {
+	if key, err := x509.ParsePKCS1PrivateKey(der); err == nil {
+		return key, nil
+	}
+	if key, err := x509.ParsePKCS8PrivateKey(der); err == nil {
+		return checkPrivate(key)
+	}
+	if key, err := x509.ParseECPrivateKey(der); err == nil {
+		return key, nil
+	}
+	if !isEncryptedPKCS8(der) {
+		return nil, errors.New("failed to parse private key, unrecognized format")
+	}
+	if len(password) == 0 || password[0] == nil {
+		return nil, errors.New("failed to parse private key, the key is encrypted and no password was provided")
+	}
+	decrypted, err := decryptPKCS8(der, password[0])
+	if err != nil {
+		return nil, err
+	}
+	key, err := x509.ParsePKCS8PrivateKey(decrypted)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse decrypted private key, %s", err.Error())
+	}
+	return checkPrivate(key)
+}
+
+func parsePublicDER(der []byte) (interface{}, error) {
+	if key, err := x509.ParsePKIXPublicKey(der); err == nil {
+		return checkPublic(key)
+	}
+	if key, err := x509.ParsePKCS1PublicKey(der); err == nil {
+		return key, nil
+	}
+	if cert, err := x509.ParseCertificate(der); err == nil {
+		return checkPublic(cert.PublicKey)
+	}
+	return nil, errors.New("failed to parse public key, unrecognized format")
+}
+
+func checkPrivate(key interface{}) (interface{}, error) {
+	switch key.(type) {
+	case *rsa.PrivateKey, *ecdsa.PrivateKey, ed25519.PrivateKey:
+		return key, nil
+	}
+	return nil, fmt.Errorf("unsupported private key type %T", key)
+}
+
+func checkPublic(key interface{}) (interface{}, error) {
+	switch key.(type) {
+	case *rsa.PublicKey, *ecdsa.PublicKey, ed25519.PublicKey:
+		return key, nil
+	}
+	return nil, fmt.Errorf("unsupported public key type %T", key)
+}
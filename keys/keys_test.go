@@ -0,0 +1,226 @@
+package keys
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const assetsDir = "../test_assets"
+
+func asset(t *testing.T, name string) []byte {
+	data, err := os.ReadFile(filepath.Join(assetsDir, name))
+	if err != nil {
+		t.Fatalf("failed to read test asset %s: %v", name, err)
+	}
+	return data
+}
+
+func TestLoadPrivateKeys(t *testing.T) {
+	expected, err := LoadPrivateKey(filepath.Join(assetsDir, "rsa.priv.pkcs1"))
+	assert.Nil(t, err)
+	assert.IsType(t, &rsa.PrivateKey{}, expected)
+
+	for _, name := range []string{"rsa.priv.pkcs1", "rsa.priv.pkcs8"} {
+		key, err := LoadPrivateKey(filepath.Join(assetsDir, name))
+		assert.Nil(t, err, name)
+		assert.True(t, expected.(*rsa.PrivateKey).Equal(key), name)
+
+		key, err = Load(filepath.Join(assetsDir, name))
+		assert.Nil(t, err, name)
+		assert.True(t, expected.(*rsa.PrivateKey).Equal(key), name)
+	}
+}
+
+func TestLoadPublicKeys(t *testing.T) {
+	private, err := LoadPrivateKey(filepath.Join(assetsDir, "rsa.priv.pkcs1"))
+	assert.Nil(t, err)
+	expected := &private.(*rsa.PrivateKey).PublicKey
+
+	for _, name := range []string{"rsa.pub.pkcs1", "rsa.pub.pkix", "rsa.cert", "rsa.priv.pkcs1", "rsa.priv.pkcs8"} {
+		key, err := LoadPublicKey(filepath.Join(assetsDir, name))
+		assert.Nil(t, err, name)
+		assert.True(t, expected.Equal(key), name)
+	}
+	for _, name := range []string{"rsa.pub.pkcs1", "rsa.pub.pkix", "rsa.cert"} {
+		key, err := Load(filepath.Join(assetsDir, name))
+		assert.Nil(t, err, name)
+		assert.True(t, expected.Equal(key), name)
+	}
+}
+
+func TestParseDER(t *testing.T) {
+	private, err := ParsePrivateKey(asset(t, "rsa.priv.pkcs1"))
+	assert.Nil(t, err)
+
+	for _, name := range []string{"rsa.priv.pkcs1", "rsa.priv.pkcs8", "rsa.pub.pkcs1", "rsa.pub.pkix", "rsa.cert"} {
+		block, _ := pem.Decode(asset(t, name))
+		assert.NotNil(t, block, name)
+		key, err := Parse(block.Bytes)
+		assert.Nil(t, err, name)
+		switch k := key.(type) {
+		case *rsa.PrivateKey:
+			assert.True(t, k.Equal(private), name)
+		case *rsa.PublicKey:
+			assert.True(t, k.Equal(private.(*rsa.PrivateKey).Public()), name)
+		default:
+			t.Errorf("%s: unexpected key type %T", name, key)
+		}
+	}
+}
+
+func TestEncryptedPKCS8(t *testing.T) {
+	expected, err := ParsePrivateKey(asset(t, "rsa.priv.pkcs1"))
+	assert.Nil(t, err)
+
+	data := asset(t, "rsa.priv.pkcs8.enc")
+	key, err := ParsePrivateKey(data, []byte("secret"))
+	assert.Nil(t, err)
+	assert.True(t, expected.(*rsa.PrivateKey).Equal(key))
+
+	key, err = ReadPrivateKey(bytes.NewReader(data), []byte("secret"))
+	assert.Nil(t, err)
+	assert.True(t, expected.(*rsa.PrivateKey).Equal(key))
+
+	_, err = ParsePrivateKey(data)
+	assert.EqualError(t, err, "failed to parse private key, the key is encrypted and no password was provided")
+
+	_, err = ParsePrivateKey(data, []byte("wrong"))
+	assert.NotNil(t, err)
+}
+
+// encryptPKCS8 encrypts the DER encoded PKCS#8 key with PBES2, PBKDF2 with HMAC-SHA256 and
+// AES-256-CBC, using the provided iteration count
+func encryptPKCS8(t *testing.T, der, password []byte, iterations int) []byte {
+	salt, iv := make([]byte, 16), make([]byte, aes.BlockSize)
+	rand.Read(salt)
+	rand.Read(iv)
+
+	kdfParams, err := asn1.Marshal(pbkdf2Params{Salt: salt, IterationCount: iterations, PRF: pkix.AlgorithmIdentifier{Algorithm: oidHMACWithSHA256, Parameters: asn1.NullRawValue}})
+	assert.Nil(t, err)
+	ivParams, err := asn1.Marshal(iv)
+	assert.Nil(t, err)
+	params, err := asn1.Marshal(pbes2Params{
+		KeyDerivationFunc: pkix.AlgorithmIdentifier{Algorithm: oidPBKDF2, Parameters: asn1.RawValue{FullBytes: kdfParams}},
+		EncryptionScheme:  pkix.AlgorithmIdentifier{Algorithm: oidAES256CBC, Parameters: asn1.RawValue{FullBytes: ivParams}},
+	})
+	assert.Nil(t, err)
+
+	data := der
+	if iterations > 0 && iterations <= maxPBKDF2Iterations {
+		key, err := pbkdf2.Key(sha256.New, string(password), salt, iterations, 32)
+		assert.Nil(t, err)
+		block, _ := aes.NewCipher(key)
+		n := aes.BlockSize - len(der)%aes.BlockSize
+		data = append(append([]byte{}, der...), bytes.Repeat([]byte{byte(n)}, n)...)
+		cipher.NewCBCEncrypter(block, iv).CryptBlocks(data, data)
+	}
+	ret, err := asn1.Marshal(encryptedPrivateKeyInfo{
+		Algorithm:     pkix.AlgorithmIdentifier{Algorithm: oidPBES2, Parameters: asn1.RawValue{FullBytes: params}},
+		EncryptedData: data,
+	})
+	assert.Nil(t, err)
+	return pem.EncodeToMemory(&pem.Block{Type: "ENCRYPTED PRIVATE KEY", Bytes: ret})
+}
+
+func TestEncryptedPKCS8Iterations(t *testing.T) {
+	expected, err := ParsePrivateKey(asset(t, "rsa.priv.pkcs1"))
+	assert.Nil(t, err)
+	der, err := x509.MarshalPKCS8PrivateKey(expected)
+	assert.Nil(t, err)
+
+	key, err := ParsePrivateKey(encryptPKCS8(t, der, []byte("secret"), 1000), []byte("secret"))
+	assert.Nil(t, err)
+	assert.True(t, expected.(*rsa.PrivateKey).Equal(key))
+
+	// Out of range iteration counts are rejected before deriving the key
+	for _, iterations := range []int{0, -1, maxPBKDF2Iterations + 1, 1 << 40} {
+		start := time.Now()
+		_, err = ParsePrivateKey(encryptPKCS8(t, der, []byte("secret"), iterations), []byte("secret"))
+		assert.NotNil(t, err, iterations)
+		assert.Contains(t, err.Error(), "invalid PBKDF2 iteration count", iterations)
+		assert.Less(t, time.Since(start), time.Second)
+	}
+}
+
+func TestCertificates(t *testing.T) {
+	certs, err := LoadCertificates(filepath.Join(assetsDir, "rsa.cert"))
+	assert.Nil(t, err)
+	assert.Len(t, certs, 1)
+	assert.Equal(t, "go-jwt test", certs[0].Subject.CommonName)
+
+	cert, err := ParseCertificate(asset(t, "rsa.cert"))
+	assert.Nil(t, err)
+	assert.True(t, cert.Equal(certs[0]))
+
+	_, err = ParseCertificate(asset(t, "rsa.pub.pkix"))
+	assert.NotNil(t, err)
+}
+
+func TestECAndEd25519Keys(t *testing.T) {
+	key, err := LoadPrivateKey(filepath.Join(assetsDir, "ec.priv.sec1"))
+	assert.Nil(t, err)
+	assert.IsType(t, &ecdsa.PrivateKey{}, key)
+	assert.Equal(t, elliptic.P256(), key.(*ecdsa.PrivateKey).Curve)
+
+	public, err := LoadPublicKey(filepath.Join(assetsDir, "ec.priv.sec1"))
+	assert.Nil(t, err)
+	assert.True(t, key.(*ecdsa.PrivateKey).PublicKey.Equal(public))
+
+	key, err = LoadPrivateKey(filepath.Join(assetsDir, "ed25519.priv.pkcs8"))
+	assert.Nil(t, err)
+	assert.IsType(t, ed25519.PrivateKey{}, key)
+
+	public, err = PublicKey(key)
+	assert.Nil(t, err)
+	assert.IsType(t, ed25519.PublicKey{}, public)
+}
+
+func TestExportRoundTrip(t *testing.T) {
+	for _, name := range []string{"rsa.priv.pkcs1", "ec.priv.sec1", "ed25519.priv.pkcs8"} {
+		key, err := ParsePrivateKey(asset(t, name))
+		assert.Nil(t, err, name)
+
+		data, err := ExportPEM(key)
+		assert.Nil(t, err, name)
+		parsed, err := ParsePrivateKey(data)
+		assert.Nil(t, err, name)
+		assert.True(t, parsed.(interface{ Equal(crypto.PrivateKey) bool }).Equal(key), name)
+
+		data, err = ExportPublicPEM(key)
+		assert.Nil(t, err, name)
+		public, err := ParsePublicKey(data)
+		assert.Nil(t, err, name)
+		expected, _ := PublicKey(key)
+		assert.Equal(t, expected, public, name)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	_, err := Parse([]byte("not a key"))
+	assert.EqualError(t, err, "failed to parse key, unrecognized DER format")
+
+	_, err = ParsePrivateKey(asset(t, "rsa.pub.pkix"))
+	assert.EqualError(t, err, "failed to parse private key, no private key found in PEM data")
+
+	_, err = Load(filepath.Join(assetsDir, "missing"))
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,81 @@
+package keys
+
+import (
+	"crypto"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/jucardi/go-jwt/encoding"
+)
+
+// Thumbprint computes the JWK thumbprint of the provided key as defined in RFC 7638 and returns
+// it base64url encoded. For asymmetric keys, the thumbprint of a private key is the same as the
+// thumbprint of its public half.
+//
+//   {key}  - The key to compute the thumbprint of. Any key supported by `ExportJWK`
+//   {hash} - (optional) The hash function to use, SHA-256 by default
+//
+func Thumbprint(key interface{}, hash ...crypto.Hash) (string, error) {
+	jwk, err := ExportJWK(key)
+	if err != nil {
+		return "", err
+	}
+	sum, err := jwk.Thumbprint(hash...)
+	if err != nil {
+		return "", err
+	}
+	return encoding.EncodeSegment(sum), nil
+}
+
+// Thumbprint computes the RFC 7638 thumbprint of this JWK: the hash of the JSON object containing
+// only the required members of the key type, in lexicographic order and without whitespace.
+//
+//   {hash} - (optional) The hash function to use, SHA-256 by default
+//
+func (j *JWK) Thumbprint(hash ...crypto.Hash) ([]byte, error) {
+	if j == nil {
+		return nil, errors.New("JWK is nil")
+	}
+	h := crypto.SHA256
+	if len(hash) > 0 {
+		h = hash[0]
+	}
+	if !h.Available() {
+		return nil, fmt.Errorf("hash function %s is not available", h)
+	}
+
+	var members [][2]string
+	switch j.KeyType {
+	case KeyTypeRSA:
+		members = [][2]string{{"e", j.E}, {"kty", j.KeyType}, {"n", j.N}}
+	case KeyTypeEC:
+		members = [][2]string{{"crv", j.Curve}, {"kty", j.KeyType}, {"x", j.X}, {"y", j.Y}}
+	case KeyTypeOKP:
+		members = [][2]string{{"crv", j.Curve}, {"kty", j.KeyType}, {"x", j.X}}
+	case KeyTypeOct:
+		members = [][2]string{{"k", j.K}, {"kty", j.KeyType}}
+	default:
+		return nil, fmt.Errorf("unsupported JWK key type '%s'", j.KeyType)
+	}
+
+	buf := []byte{'{'}
+	for i, m := range members {
+		if m[1] == "" {
+			return nil, fmt.Errorf("invalid JWK, missing required member '%s'", m[0])
+		}
+		if i > 0 {
+			buf = append(buf, ',')
+		}
+		name, _ := json.Marshal(m[0])
+		value, _ := json.Marshal(m[1])
+		buf = append(buf, name...)
+		buf = append(buf, ':')
+		buf = append(buf, value...)
+	}
+	buf = append(buf, '}')
+
+	hasher := h.New()
+	hasher.Write(buf)
+	return hasher.Sum(nil), nil
+}
@@ -0,0 +1,94 @@
+package keys
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"testing"
+
+	"github.com/jucardi/go-jwt/encoding"
+	"github.com/stretchr/testify/assert"
+)
+
+// rfc7638Key is the RSA key of the example in RFC 7638 section 3.1
+var rfc7638Key = &JWK{
+	KeyType:   KeyTypeRSA,
+	KeyID:     "2011-04-29",
+	Algorithm: "RS256",
+	N: "0vx7agoebGcQSuuPiLJXZptN9nndrQmbXEps2aiAFbWhM78LhWx4cbbfAAtVT86zwu1RK7aPFFxuhDR1L6tSoc_BJECPebWKRXjBZCiFV4n3oknjhMstn" +
+		"64tZ_2W-5JsGY4Hc5n9yBXArwl93lqt7_RN5w6Cf0h4QyQ5v-65YGjQR0_FDW2QvzqY368QQMicAtaSqzs8KJZgnYb9c7d0zgdAZHzu6qMQvRL5hajrn1n9" +
+		"1CbOpbISD08qNLyrdkt-bFTWhAI4vMQFh6WeZu0fM4lFd2NcRwr3XPksINHaQ-G_xBniIqbw0Ls1jF44-csFCur-kEgU8awapJzKnqDKgw",
+	E: "AQAB",
+}
+
+func sha256Segment(data string) string {
+	sum := sha256.Sum256([]byte(data))
+	return encoding.EncodeSegment(sum[:])
+}
+
+func TestThumbprintRFC7638(t *testing.T) {
+	sum, err := rfc7638Key.Thumbprint()
+	assert.Nil(t, err)
+	assert.Equal(t, "NzbLsXh8uDCcd-6MNwXF4W_7noWXFZAfHkxZsRGC9Xs", encoding.EncodeSegment(sum))
+
+	// The optional members do not change the thumbprint
+	key, err := rfc7638Key.Key()
+	assert.Nil(t, err)
+	thumbprint, err := Thumbprint(key)
+	assert.Nil(t, err)
+	assert.Equal(t, "NzbLsXh8uDCcd-6MNwXF4W_7noWXFZAfHkxZsRGC9Xs", thumbprint)
+
+	sum, err = rfc7638Key.Thumbprint(crypto.SHA512)
+	assert.Nil(t, err)
+	assert.Len(t, sum, 64)
+}
+
+func TestThumbprintRFC8037(t *testing.T) {
+	// RFC 8037 appendix A.3
+	jwk := &JWK{KeyType: KeyTypeOKP, Curve: "Ed25519", X: "11qYAYKxCrfVS_7TyWQHOg7hcvPapiMlrwIaaPcHURo"}
+	sum, err := jwk.Thumbprint()
+	assert.Nil(t, err)
+	assert.Equal(t, "kPrK_qmxVWaYVA9wwBF6Iuo3vVzz7TxHCTwXBygrS4k", encoding.EncodeSegment(sum))
+}
+
+func TestThumbprintMembers(t *testing.T) {
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.Nil(t, err)
+	jwk, err := ExportJWK(&ecKey.PublicKey)
+	assert.Nil(t, err)
+	expected := sha256Segment(`{"crv":"P-256","kty":"EC","x":"` + jwk.X + `","y":"` + jwk.Y + `"}`)
+	for _, key := range []interface{}{ecKey, &ecKey.PublicKey} {
+		thumbprint, err := Thumbprint(key)
+		assert.Nil(t, err)
+		assert.Equal(t, expected, thumbprint)
+	}
+
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	assert.Nil(t, err)
+	jwk, err = ExportJWK(pub)
+	assert.Nil(t, err)
+	expected = sha256Segment(`{"crv":"Ed25519","kty":"OKP","x":"` + jwk.X + `"}`)
+	for _, key := range []interface{}{priv, pub} {
+		thumbprint, err := Thumbprint(key)
+		assert.Nil(t, err)
+		assert.Equal(t, expected, thumbprint)
+	}
+
+	thumbprint, err := Thumbprint([]byte("secret"))
+	assert.Nil(t, err)
+	assert.Equal(t, sha256Segment(`{"k":"c2VjcmV0","kty":"oct"}`), thumbprint)
+}
+
+func TestThumbprintErrors(t *testing.T) {
+	_, err := (&JWK{KeyType: KeyTypeEC, Curve: "P-256", X: "x"}).Thumbprint()
+	assert.Contains(t, err.Error(), "missing required member 'y'")
+	_, err = (&JWK{KeyType: "unknown"}).Thumbprint()
+	assert.Contains(t, err.Error(), "unsupported JWK key type")
+	_, err = (*JWK)(nil).Thumbprint()
+	assert.NotNil(t, err)
+	_, err = rfc7638Key.Thumbprint(crypto.Hash(999))
+	assert.NotNil(t, err)
+}
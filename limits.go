@@ -0,0 +1,104 @@
+package jwt
+
+import "encoding/base64"
+
+// Limits defines the size and structure limits enforced when parsing untrusted tokens. A zero
+// value uses the corresponding value of `DefaultLimits` and a negative value disables the check.
+type Limits struct {
+	// MaxTokenLength is the maximum length in bytes of the token string
+	MaxTokenLength int
+	// MaxHeaderSize is the maximum size in bytes of the decoded header
+	MaxHeaderSize int
+	// MaxClaimsSize is the maximum size in bytes of the decoded claims
+	MaxClaimsSize int
+	// MaxJSONDepth is the maximum nesting depth of objects and arrays in the header and claims
+	MaxJSONDepth int
+	// MaxClaims is the maximum number of top level claims
+	MaxClaims int
+}
+
+// DefaultLimits are the limits enforced by `Parse` unless `WithLimits` is provided
+var DefaultLimits = Limits{
+	MaxTokenLength: 64 * 1024,
+	MaxHeaderSize:  16 * 1024,
+	MaxClaimsSize:  32 * 1024,
+	MaxJSONDepth:   32,
+	MaxClaims:      256,
+}
+
+// resolve returns a copy of the limits with the zero values replaced by the defaults
+func (l Limits) resolve() Limits {
+	if l.MaxTokenLength == 0 {
+		l.MaxTokenLength = DefaultLimits.MaxTokenLength
+	}
+	if l.MaxHeaderSize == 0 {
+		l.MaxHeaderSize = DefaultLimits.MaxHeaderSize
+	}
+	if l.MaxClaimsSize == 0 {
+		l.MaxClaimsSize = DefaultLimits.MaxClaimsSize
+	}
+	if l.MaxJSONDepth == 0 {
+		l.MaxJSONDepth = DefaultLimits.MaxJSONDepth
+	}
+	if l.MaxClaims == 0 {
+		l.MaxClaims = DefaultLimits.MaxClaims
+	}
+	return l
+}
+
+func (l Limits) checkTokenLength(token string) error {
+	if l.MaxTokenLength > 0 && len(token) > l.MaxTokenLength {
+		return newErrorf(ErrLimitExceeded, "token length %d exceeds the maximum of %d bytes", len(token), l.MaxTokenLength)
+	}
+	return nil
+}
+
+// checkSegmentSize verifies the decoded size of the encoded segment before decoding it
+func checkSegmentSize(name, segment string, max int) error {
+	if max <= 0 {
+		return nil
+	}
+	if size := base64.RawURLEncoding.DecodedLen(len(segment)); size > max {
+		return newErrorf(ErrLimitExceeded, "token %s size %d exceeds the maximum of %d bytes", name, size, max)
+	}
+	return nil
+}
+
+// checkJSON verifies the nesting depth of the JSON document and, if it is an object, the number of
+// top level members. It does not validate the JSON syntax, which is left to the decoder.
+func (l Limits) checkJSON(name string, data []byte, countMembers bool) error {
+	depth, members := 0, 0
+	inString, escaped := false, false
+	for _, c := range data {
+		if inString {
+			switch {
+			case escaped:
+				escaped = false
+			case c == '\\':
+				escaped = true
+			case c == '"':
+				inString = false
+			}
+			continue
+		}
+		switch c {
+		case '"':
+			inString = true
+		case '{', '[':
+			depth++
+			if l.MaxJSONDepth > 0 && depth > l.MaxJSONDepth {
+				return newErrorf(ErrLimitExceeded, "token %s exceeds the maximum JSON depth of %d", name, l.MaxJSONDepth)
+			}
+		case '}', ']':
+			depth--
+		case ':':
+			if depth == 1 {
+				members++
+			}
+		}
+	}
+	if countMembers && l.MaxClaims > 0 && members > l.MaxClaims {
+		return newErrorf(ErrLimitExceeded, "token %s has %d members, exceeding the maximum of %d", name, members, l.MaxClaims)
+	}
+	return nil
+}
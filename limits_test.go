@@ -0,0 +1,101 @@
+package jwt
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/jucardi/go-jwt/encoding"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	limitsHeader = `{"alg":"HS256","typ":"JWT"}`
+	limitsClaims = `{"sub":"alice","scope":"read write"}`
+)
+
+func parseWithLimits(token string, limits Limits) error {
+	_, err := ParseAndValidate(token, &ExtendedClaims{}, testSecret, WithLimits(limits))
+	return err
+}
+
+func TestLimitsSizes(t *testing.T) {
+	token := signRaw(t, limitsHeader, limitsClaims)
+
+	assert.Nil(t, parseWithLimits(token, Limits{MaxTokenLength: len(token)}))
+	err := parseWithLimits(token, Limits{MaxTokenLength: len(token) - 1})
+	assert.True(t, ErrLimitExceeded.IsType(err))
+	assert.Contains(t, err.Error(), "token length")
+
+	assert.Nil(t, parseWithLimits(token, Limits{MaxHeaderSize: len(limitsHeader)}))
+	err = parseWithLimits(token, Limits{MaxHeaderSize: len(limitsHeader) - 1})
+	assert.True(t, ErrLimitExceeded.IsType(err))
+	assert.Contains(t, err.Error(), "header size")
+
+	assert.Nil(t, parseWithLimits(token, Limits{MaxClaimsSize: len(limitsClaims)}))
+	err = parseWithLimits(token, Limits{MaxClaimsSize: len(limitsClaims) - 1})
+	assert.True(t, ErrLimitExceeded.IsType(err))
+	assert.Contains(t, err.Error(), "claims size")
+
+	// Negative values disable the checks
+	assert.Nil(t, parseWithLimits(token, Limits{MaxTokenLength: -1, MaxHeaderSize: -1, MaxClaimsSize: -1}))
+}
+
+func TestLimitsBeforeDecoding(t *testing.T) {
+	// The segments are not valid base64url, so decoding them would fail with ErrMalformedToken
+	garbage := strings.Repeat("!", 100)
+
+	err := parseWithLimits(garbage+"."+garbage+"."+garbage, Limits{MaxTokenLength: 300})
+	assert.True(t, ErrLimitExceeded.IsType(err))
+	err = parseWithLimits(garbage+".e30.", Limits{MaxHeaderSize: 74})
+	assert.True(t, ErrLimitExceeded.IsType(err))
+	err = parseWithLimits("e30."+garbage+".", Limits{MaxClaimsSize: 74})
+	assert.True(t, ErrLimitExceeded.IsType(err))
+
+	// Within the limits, the same segments fail to decode
+	err = parseWithLimits(garbage+".e30.", Limits{MaxHeaderSize: 75})
+	assert.True(t, ErrMalformedToken.IsType(err))
+	err = parseWithLimits("e30."+garbage+".", Limits{MaxClaimsSize: 75})
+	assert.True(t, ErrMalformedToken.IsType(err))
+}
+
+func TestLimitsSegments(t *testing.T) {
+	token := signRaw(t, limitsHeader, limitsClaims)
+	for _, tc := range []string{
+		"",
+		"e30",
+		"e30.e30",
+		token + ".e30",
+		"!.!.!.!",
+		strings.Repeat(".", 1000),
+	} {
+		_, err := Parse(tc, &ExtendedClaims{})
+		assert.True(t, ErrMalformedToken.IsType(err), tc)
+		assert.Contains(t, err.Error(), "unexpected number of pieces", tc)
+	}
+}
+
+func TestLimitsJSON(t *testing.T) {
+	claims := `{"a":1,"b":2,"c":{"d":3}}`
+	token := signRaw(t, limitsHeader, claims)
+	assert.Nil(t, parseWithLimits(token, Limits{MaxClaims: 3}))
+	err := parseWithLimits(token, Limits{MaxClaims: 2})
+	assert.True(t, ErrLimitExceeded.IsType(err))
+	assert.Contains(t, err.Error(), "has 3 members")
+
+	// Depth 2 in the claims, the header has depth 1
+	assert.Nil(t, parseWithLimits(token, Limits{MaxJSONDepth: 2}))
+	err = parseWithLimits(token, Limits{MaxJSONDepth: 1})
+	assert.True(t, ErrLimitExceeded.IsType(err))
+	assert.Contains(t, err.Error(), "maximum JSON depth")
+
+	// Members and brackets in strings are not counted
+	token = signRaw(t, limitsHeader, `{"a":"{[:,\"{:"}`)
+	assert.Nil(t, parseWithLimits(token, Limits{MaxClaims: 1, MaxJSONDepth: 1}))
+
+	// The limits are checked before the header is decoded, invalid JSON is not unmarshalled
+	header := encoding.EncodeSegment([]byte(`{"alg":"HS256","x":[[[[`))
+	_, err = Parse(header+".e30.", &ExtendedClaims{}, WithLimits(Limits{MaxJSONDepth: 3}))
+	assert.True(t, ErrLimitExceeded.IsType(err))
+	_, err = Parse(header+".e30.", &ExtendedClaims{}, WithLimits(Limits{MaxJSONDepth: 5}))
+	assert.True(t, ErrUnmarshalFailed.IsType(err))
+}
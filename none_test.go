@@ -0,0 +1,44 @@
+package jwt
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/jucardi/go-jwt/signing"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUnsecuredTokens(t *testing.T) {
+	token, err := Sign(testClaims(), signing.UnsafeAllowNone)
+	assert.Nil(t, err)
+	assert.True(t, strings.HasSuffix(token, "."), "unsecured tokens have an empty signature")
+
+	// Rejected by default, whatever the key
+	for _, key := range []interface{}{testSecret, "secret", KeyResolverFunc(func(TokenHeader) (interface{}, error) { return testSecret, nil })} {
+		_, err = ParseAndValidate(token, &ExtendedClaims{}, key)
+		assert.True(t, ErrUnsecuredToken.IsType(err))
+	}
+
+	claims := &ExtendedClaims{}
+	data, err := ParseAndValidate(token, claims, signing.UnsafeAllowNone)
+	assert.Nil(t, err)
+	assert.Equal(t, signing.AlgorithmNone, data.Algorithm)
+	assert.Equal(t, "alice", claims.Subject)
+
+	// An unsecured token must not carry a signature
+	_, err = ParseAndValidate(token+"c2lnbmF0dXJl", &ExtendedClaims{}, signing.UnsafeAllowNone)
+	assert.True(t, ErrInvalidSignature.IsType(err))
+
+	// Nor can a signed token be verified as an unsecured one
+	signed, err := Sign(testClaims(), testSecret)
+	assert.Nil(t, err)
+	_, err = ParseAndValidate(signed, &ExtendedClaims{}, signing.UnsafeAllowNone)
+	assert.True(t, ErrInvalidKey.IsType(err))
+}
+
+func TestUnsecuredTokensSignKey(t *testing.T) {
+	for _, key := range []interface{}{testSecret, "I understand unsecured tokens can be forged by anyone"} {
+		_, err := Sign(testClaims(), key, signing.AlgorithmNone)
+		assert.True(t, ErrInvalidKey.IsType(err))
+	}
+}
@@ -0,0 +1,272 @@
+package jwt
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// NumericDateLenient accepts JSON numbers, numeric strings and RFC 3339 strings
+	NumericDateLenient NumericDateMode = iota
+	// NumericDateStrict only accepts JSON numbers, as required by RFC 7519
+	NumericDateStrict
+)
+
+// NumericDateMode indicates which representations are accepted when parsing a NumericDate
+type NumericDateMode int
+
+// NumericDateParsing is the mode used when unmarshalling NumericDate claims
+var NumericDateParsing = NumericDateLenient
+
+// NumericDate represents a JWT NumericDate as defined in RFC 7519: the number of seconds since the
+// UNIX epoch, possibly with a fractional part. Fractional seconds are preserved up to nanosecond
+// precision.
+type NumericDate struct {
+	time.Time
+}
+
+// NewNumericDate creates a new NumericDate from the provided time
+//
+//   {t} - The time
+//
+func NewNumericDate(t time.Time) *NumericDate {
+	return &NumericDate{Time: t}
+}
+
+// NumericDateFromUnix creates a new NumericDate from UNIX time in seconds. Returns nil for zero,
+// which represents an absent claim.
+//
+//   {seconds} - The UNIX time in seconds
+//
+func NumericDateFromUnix(seconds int64) *NumericDate {
+	if seconds == 0 {
+		return nil
+	}
+	return &NumericDate{Time: time.Unix(seconds, 0)}
+}
+
+// ParseNumericDate parses a NumericDate from a decoded JSON value: float64, json.Number, integers
+// and, in lenient mode, numeric and RFC 3339 strings. Nil values return nil.
+//
+//   {value} - The decoded JSON value
+//   {mode}  - (optional) The parsing mode, `NumericDateParsing` by default
+//
+func ParseNumericDate(value interface{}, mode ...NumericDateMode) (*NumericDate, error) {
+	m := NumericDateParsing
+	if len(mode) > 0 {
+		m = mode[0]
+	}
+	switch v := value.(type) {
+	case nil:
+		return nil, nil
+	case *NumericDate:
+		return v, nil
+	case NumericDate:
+		return &v, nil
+	case time.Time:
+		return NewNumericDate(v), nil
+	case int:
+		return NewNumericDate(time.Unix(int64(v), 0)), nil
+	case int64:
+		return NewNumericDate(time.Unix(v, 0)), nil
+	case float64:
+		return parseNumericDateNumber(strconv.FormatFloat(v, 'f', -1, 64))
+	case json.Number:
+		return parseNumericDateNumber(v.String())
+	case string:
+		if m == NumericDateStrict {
+			return nil, fmt.Errorf("invalid NumericDate %q, strings are not accepted in strict mode", v)
+		}
+		if ret, err := parseNumericDateNumber(v); err == nil {
+			return ret, nil
+		}
+		t, err := time.Parse(time.RFC3339Nano, v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid NumericDate %q, expected a number or an RFC 3339 date", v)
+		}
+		return NewNumericDate(t), nil
+	}
+	return nil, fmt.Errorf("invalid NumericDate of type %T", value)
+}
+
+// Unix returns the time in seconds since the UNIX epoch, truncating fractional seconds. Returns
+// zero if the NumericDate is nil.
+func (d *NumericDate) Unix() int64 {
+	if d == nil {
+		return 0
+	}
+	return d.Time.Unix()
+}
+
+// MarshalJSON encodes the NumericDate as a JSON number, with a fractional part only if the time
+// has sub-second precision
+func (d NumericDate) MarshalJSON() ([]byte, error) {
+	sec, nsec := d.Time.Unix(), int64(d.Time.Nanosecond())
+	if nsec == 0 {
+		return []byte(strconv.FormatInt(sec, 10)), nil
+	}
+	sign := ""
+	if sec < 0 {
+		// time.Unix floors the seconds, so -0.5 is represented as -1 seconds and 500000000 nanos
+		sign, sec, nsec = "-", -(sec + 1), 1e9-nsec
+	}
+	frac := strings.TrimRight(fmt.Sprintf("%09d", nsec), "0")
+	return []byte(fmt.Sprintf("%s%d.%s", sign, sec, frac)), nil
+}
+
+// UnmarshalJSON decodes a NumericDate according to `NumericDateParsing`
+func (d *NumericDate) UnmarshalJSON(data []byte) error {
+	// Fast path for plain decimal numbers, the common case, which avoids allocating
+	if sec, nsec, ok := parseDecimalSeconds(data); ok {
+		d.Time = time.Unix(sec, nsec)
+		return nil
+	}
+	var value interface{}
+	dec := json.NewDecoder(strings.NewReader(string(data)))
+	dec.UseNumber()
+	if err := dec.Decode(&value); err != nil {
+		return err
+	}
+	ret, err := ParseNumericDate(value)
+	if err != nil {
+		return err
+	}
+	if ret == nil {
+		*d = NumericDate{}
+		return nil
+	}
+	*d = *ret
+	return nil
+}
+
+// parseNumericDateNumber parses a decimal number of seconds exactly, with nanosecond precision
+func parseNumericDateNumber(s string) (*NumericDate, error) {
+	if sec, nsec, ok := parseDecimalSeconds([]byte(s)); ok {
+		return &NumericDate{Time: time.Unix(sec, nsec)}, nil
+	}
+	if err := checkNumericDateNumber(s); err != nil {
+		return nil, err
+	}
+	r, ok := new(big.Rat).SetString(s)
+	if !ok {
+		return nil, fmt.Errorf("invalid NumericDate %q", s)
+	}
+	nanos := new(big.Rat).Mul(r, big.NewRat(1e9, 1))
+	// Round to the nearest nanosecond
+	n := new(big.Int).Quo(nanos.Num(), nanos.Denom())
+	if rem := new(big.Int).Sub(nanos.Num(), new(big.Int).Mul(n, nanos.Denom())); rem.Sign() != 0 {
+		if new(big.Int).Mul(new(big.Int).Abs(rem), big.NewInt(2)).Cmp(nanos.Denom()) >= 0 {
+			n.Add(n, big.NewInt(int64(rem.Sign())))
+		}
+	}
+	sec, nsec := new(big.Int).DivMod(n, big.NewInt(1e9), new(big.Int))
+	if !sec.IsInt64() {
+		return nil, fmt.Errorf("invalid NumericDate %q, out of range", s)
+	}
+	return &NumericDate{Time: time.Unix(sec.Int64(), nsec.Int64())}, nil
+}
+
+// maxNumericDateDigits is the maximum number of digits of the integer part of a NumericDate, the
+// number of digits of the largest int64
+const maxNumericDateDigits = 19
+
+// maxNumericDateExponent bounds the exponent of a NumericDate, so converting untrusted values is
+// cheap. Larger exponents are only valid for zeros or numbers out of range.
+const maxNumericDateExponent = 1000
+
+// checkNumericDateNumber verifies the number follows the JSON number grammar (RFC 8259) and that
+// its integer part fits in an int64, before it is converted exactly
+func checkNumericDateNumber(s string) error {
+	isDigit := func(i int) bool { return i < len(s) && s[i] >= '0' && s[i] <= '9' }
+	i := 0
+	if i < len(s) && s[i] == '-' {
+		i++
+	}
+	start := i
+	for isDigit(i) {
+		i++
+	}
+	integer := s[start:i]
+	if len(integer) == 0 || len(integer) > 1 && integer[0] == '0' {
+		return fmt.Errorf("invalid NumericDate %q", s)
+	}
+	var frac string
+	if i < len(s) && s[i] == '.' {
+		i++
+		start = i
+		for isDigit(i) {
+			i++
+		}
+		if frac = s[start:i]; frac == "" {
+			return fmt.Errorf("invalid NumericDate %q", s)
+		}
+	}
+	exp := 0
+	if i < len(s) && (s[i] == 'e' || s[i] == 'E') {
+		i++
+		neg := i < len(s) && s[i] == '-'
+		if i < len(s) && (s[i] == '-' || s[i] == '+') {
+			i++
+		}
+		if !isDigit(i) {
+			return fmt.Errorf("invalid NumericDate %q", s)
+		}
+		for ; isDigit(i); i++ {
+			if exp = exp*10 + int(s[i]-'0'); exp > maxNumericDateExponent {
+				return fmt.Errorf("invalid NumericDate %q, out of range", s)
+			}
+		}
+		if neg {
+			exp = -exp
+		}
+	}
+	if i != len(s) {
+		return fmt.Errorf("invalid NumericDate %q", s)
+	}
+	// Number of digits of the integer part once the exponent is applied
+	digits := strings.TrimLeft(integer+frac, "0")
+	if digits != "" && len(digits)-len(frac)+exp > maxNumericDateDigits {
+		return fmt.Errorf("invalid NumericDate %q, out of range", s)
+	}
+	return nil
+}
+
+// parseDecimalSeconds parses numbers in the form `-?digits(.digits)?` without leading zeros, with at
+// most 18 integer digits and 9 fractional digits, which are exactly representable in seconds and
+// nanoseconds. Returns false for any other input, which must be parsed with `parseNumericDateNumber`.
+func parseDecimalSeconds(data []byte) (sec, nsec int64, ok bool) {
+	neg := len(data) > 0 && data[0] == '-'
+	if neg {
+		data = data[1:]
+	}
+	i := 0
+	for ; i < len(data) && data[i] >= '0' && data[i] <= '9'; i++ {
+		sec = sec*10 + int64(data[i]-'0')
+	}
+	if i == 0 || i > 18 || i > 1 && data[0] == '0' {
+		return 0, 0, false
+	}
+	if i < len(data) {
+		if data[i] != '.' || len(data)-i-1 < 1 || len(data)-i-1 > 9 {
+			return 0, 0, false
+		}
+		frac := data[i+1:]
+		for j := 0; j < 9; j++ {
+			nsec *= 10
+			if j < len(frac) {
+				if frac[j] < '0' || frac[j] > '9' {
+					return 0, 0, false
+				}
+				nsec += int64(frac[j] - '0')
+			}
+		}
+	}
+	if neg {
+		sec, nsec = -sec, -nsec
+	}
+	return sec, nsec, true
+}
@@ -0,0 +1,81 @@
+package jwt
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNumericDateJSON(t *testing.T) {
+	for input, expected := range map[string]time.Time{
+		`1500000000`:             time.Unix(1500000000, 0),
+		`1500000000.5`:           time.Unix(1500000000, 5e8),
+		`1500000000.123456789`:   time.Unix(1500000000, 123456789),
+		`1.5e9`:                  time.Unix(1500000000, 0),
+		`15E8`:                   time.Unix(1500000000, 0),
+		`1500000000500e-3`:       time.Unix(1500000000, 5e8),
+		`-0.5`:                   time.Unix(-1, 5e8),
+		`0`:                      time.Unix(0, 0),
+		`"1500000000"`:           time.Unix(1500000000, 0),
+		`"2017-07-14T02:40:00Z"`: time.Unix(1500000000, 0),
+	} {
+		var d NumericDate
+		assert.Nil(t, json.Unmarshal([]byte(input), &d), input)
+		assert.True(t, expected.Equal(d.Time), "%s: %s", input, d.Time)
+	}
+
+	for _, d := range []*NumericDate{
+		NumericDateFromUnix(1500000000),
+		NewNumericDate(time.Unix(1500000000, 123456789)),
+		NewNumericDate(time.Unix(-1, 5e8)),
+	} {
+		data, err := json.Marshal(d)
+		assert.Nil(t, err)
+		var parsed NumericDate
+		assert.Nil(t, json.Unmarshal(data, &parsed))
+		assert.True(t, d.Equal(parsed.Time), string(data))
+	}
+}
+
+func TestNumericDateInvalid(t *testing.T) {
+	for _, input := range []string{
+		`"0x10"`, `"1/2"`, `"01"`, `"1."`, `".5"`, `"1e"`, `"+1"`, `"NaN"`, `"Infinity"`, `" 1"`,
+		`1e1000000`, `-1e1000000`, `1e20`, `99999999999999999999`, `"1e1000000"`, `true`, `{}`,
+	} {
+		var d NumericDate
+		start := time.Now()
+		assert.NotNil(t, json.Unmarshal([]byte(input), &d), input)
+		assert.Less(t, time.Since(start), 10*time.Millisecond, input)
+	}
+}
+
+func TestNumericDateStrict(t *testing.T) {
+	_, err := ParseNumericDate("1500000000", NumericDateStrict)
+	assert.NotNil(t, err)
+
+	d, err := ParseNumericDate(json.Number("1500000000.25"), NumericDateStrict)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1500000000), d.Unix())
+	assert.Equal(t, 250000000, d.Nanosecond())
+
+	d, err = ParseNumericDate(float64(1500000000))
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1500000000), d.Unix())
+
+	_, err = ParseNumericDate(1e300)
+	assert.NotNil(t, err)
+}
+
+func TestNumericDateClaims(t *testing.T) {
+	token := signRaw(t, `{"alg":"HS256","typ":"JWT"}`, `{"sub":"alice","exp":1e1000000}`)
+	start := time.Now()
+	_, err := Parse(token, &ExtendedClaims{})
+	assert.True(t, ErrUnmarshalFailed.IsType(err))
+	assert.Less(t, time.Since(start), 10*time.Millisecond)
+
+	claims := MapClaims{"exp": "1/2", "iat": 1500000000.5}
+	assert.Nil(t, claims.ExpiresAt())
+	assert.Equal(t, 500000000, claims.IssuedAt().Nanosecond())
+}
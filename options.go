@@ -0,0 +1,291 @@
+package jwt
+
+import (
+	"crypto"
+
+	"github.com/jucardi/go-jwt/encryption"
+)
+
+// SignOption configures how `TokenData.Sign` produces a token
+type SignOption func(*signOptions)
+
+type signOptions struct {
+	thumbprintKeyID bool
+	thumbprintHash  crypto.Hash
+	mediaType       string
+	codec           Codec
+}
+
+// WithSigningCodec sets the Codec used to marshal the header and claims of the token, instead of
+// the one set with `SetCodec`
+//
+//   {codec} - The codec to marshal the token with
+//
+func WithSigningCodec(codec Codec) SignOption {
+	return func(o *signOptions) {
+		o.codec = codec
+	}
+}
+
+// WithType sets the "typ" header to an explicit media type, e.g. `MediaTypeAccessToken`, so
+// tokens meant for different purposes cannot be substituted for one another when the receiving
+// side restricts the accepted types with `WithAcceptedTypes`.
+//
+//   {typ} - The media type of the token
+//
+func WithType(typ string) SignOption {
+	return func(o *signOptions) {
+		o.mediaType = typ
+	}
+}
+
+// WithThumbprintKeyID sets the "kid" header to the RFC 7638 JWK thumbprint of the public half of
+// the signing key, so key IDs are stable without maintaining a registry. Only asymmetric keys are
+// supported, since the thumbprint of an HMAC secret would expose a hash of the secret.
+//
+//   {hash} - (optional) The hash function to compute the thumbprint with, SHA-256 by default
+//
+func WithThumbprintKeyID(hash ...crypto.Hash) SignOption {
+	return func(o *signOptions) {
+		o.thumbprintKeyID = true
+		o.thumbprintHash = crypto.SHA256
+		if len(hash) > 0 {
+			o.thumbprintHash = hash[0]
+		}
+	}
+}
+
+func newSignOptions(options []SignOption) *signOptions {
+	ret := &signOptions{}
+	for _, opt := range options {
+		if opt != nil {
+			opt(ret)
+		}
+	}
+	if ret.codec == nil {
+		ret.codec = GetCodec()
+	}
+	return ret
+}
+
+// EncryptOption configures how `Encrypt` and `EncryptNested` produce an encrypted token
+type EncryptOption func(*encryptOptions)
+
+type encryptOptions struct {
+	algorithm   encryption.KeyAlgorithm
+	encryption  encryption.ContentEncryption
+	keyID       string
+	contentType string
+}
+
+// WithKeyAlgorithm sets the key management algorithm used to encrypt the content encryption key,
+// instead of the default determined from the key with `encryption.DefaultFromKey`
+//
+//   {alg} - The key management algorithm, e.g. `encryption.AlgorithmRSAOAEP256`
+//
+func WithKeyAlgorithm(alg encryption.KeyAlgorithm) EncryptOption {
+	return func(o *encryptOptions) {
+		o.algorithm = alg
+	}
+}
+
+// WithContentEncryption sets the algorithm used to encrypt the content, A256GCM by default
+//
+//   {enc} - The content encryption algorithm, e.g. `encryption.EncryptionA128CBCHS256`
+//
+func WithContentEncryption(enc encryption.ContentEncryption) EncryptOption {
+	return func(o *encryptOptions) {
+		o.encryption = enc
+	}
+}
+
+// WithEncryptionKeyID sets the "kid" header of the encrypted token, so the recipient can resolve
+// the decryption key with a KeyResolver
+//
+//   {kid} - The ID of the key the content encryption key is encrypted with
+//
+func WithEncryptionKeyID(kid string) EncryptOption {
+	return func(o *encryptOptions) {
+		o.keyID = kid
+	}
+}
+
+// WithContentType sets the "cty" header of the encrypted token. `EncryptNested` always sets it to
+// "JWT".
+//
+//   {cty} - The media type of the encrypted content
+//
+func WithContentType(cty string) EncryptOption {
+	return func(o *encryptOptions) {
+		o.contentType = cty
+	}
+}
+
+func newEncryptOptions(options []EncryptOption) *encryptOptions {
+	ret := &encryptOptions{
+		encryption: encryption.EncryptionA256GCM,
+	}
+	for _, opt := range options {
+		if opt != nil {
+			opt(ret)
+		}
+	}
+	return ret
+}
+
+// ParseOption configures how `Parse` and `ParseAndValidate` process a token
+type ParseOption func(*parseOptions)
+
+// CriticalHeaderHandler processes the value of a critical header extension listed in the "crit"
+// header. Returning an error rejects the token.
+type CriticalHeaderHandler func(value interface{}) error
+
+type parseOptions struct {
+	critical      map[string]CriticalHeaderHandler
+	acceptedTypes []string
+	limits        Limits
+	strict        bool
+	codec         Codec
+	cache         *VerifiedCache
+	workers       int
+	revocation    RevocationStore
+	replay        *ReplayGuard
+}
+
+// WithCodec sets the Codec used to unmarshal the header and claims of the token, instead of the
+// one set with `SetCodec`
+//
+//   {codec} - The codec to unmarshal the token with
+//
+func WithCodec(codec Codec) ParseOption {
+	return func(o *parseOptions) {
+		o.codec = codec
+	}
+}
+
+// WithStrictDecoding enables or disables strict decoding, overriding `StrictDecoding`. In strict
+// mode, tokens with non-canonical base64url segments (padding, standard alphabet characters,
+// non-zero trailing bits), duplicate header or claim names, or trailing data are rejected.
+//
+//   {enabled} - (optional) Whether strict decoding is enabled, true if not provided
+//
+func WithStrictDecoding(enabled ...bool) ParseOption {
+	return func(o *parseOptions) {
+		o.strict = len(enabled) == 0 || enabled[0]
+	}
+}
+
+// WithLimits replaces the size and structure limits enforced when parsing the token. Zero values
+// use the corresponding value of `DefaultLimits` and negative values disable the check.
+//
+//   {limits} - The limits to enforce
+//
+func WithLimits(limits Limits) ParseOption {
+	return func(o *parseOptions) {
+		o.limits = limits
+	}
+}
+
+// WithAcceptedTypes restricts the "typ" header values accepted when parsing, replacing the default
+// which only accepts "JWT". Values are compared case-insensitively and the "application/" prefix
+// is optional, as per RFC 7515. An empty string allows tokens without a "typ" header.
+//
+//   {types} - The accepted media types, e.g. `MediaTypeAccessToken`
+//
+func WithAcceptedTypes(types ...string) ParseOption {
+	return func(o *parseOptions) {
+		o.acceptedTypes = types
+	}
+}
+
+// WithCriticalHeader registers a handler for a critical header extension. Tokens listing critical
+// extensions in the "crit" header that have no registered handler are rejected.
+//
+//   {name}    - The header extension name
+//   {handler} - The handler to process the extension value, may be nil if any value is accepted
+//
+func WithCriticalHeader(name string, handler CriticalHeaderHandler) ParseOption {
+	return func(o *parseOptions) {
+		if o.critical == nil {
+			o.critical = map[string]CriticalHeaderHandler{}
+		}
+		o.critical[name] = handler
+	}
+}
+
+// WithVerifiedCache caches successful signature verifications in the provided cache, so tokens
+// presented repeatedly are not verified again while cached. Only used by `ParseAndValidate`.
+//
+//   {cache} - The cache of verified tokens
+//
+func WithVerifiedCache(cache *VerifiedCache) ParseOption {
+	return func(o *parseOptions) {
+		o.cache = cache
+	}
+}
+
+// WithRevocation rejects the tokens revoked in the provided store with an ErrRevoked error, once
+// the signature and claims are valid. Only used by the functions that validate the token.
+//
+//   {store} - The revocation store
+//
+func WithRevocation(store RevocationStore) ParseOption {
+	return func(o *parseOptions) {
+		o.revocation = store
+	}
+}
+
+// WithReplayGuard rejects the second presentation of single-use tokens with an ErrReplayed error,
+// once the signature and claims are valid and the token is not revoked. Only used by the functions
+// that validate the token.
+//
+//   {guard} - The replay guard
+//
+func WithReplayGuard(guard *ReplayGuard) ParseOption {
+	return func(o *parseOptions) {
+		o.replay = guard
+	}
+}
+
+// WithWorkers sets the number of tokens verified concurrently by `VerifyBatch` and `VerifyStream`,
+// which default to GOMAXPROCS. Only used by the batch APIs.
+//
+//   {workers} - The number of concurrent workers
+//
+func WithWorkers(workers int) ParseOption {
+	return func(o *parseOptions) {
+		o.workers = workers
+	}
+}
+
+func newParseOptions(options []ParseOption) *parseOptions {
+	ret := &parseOptions{
+		acceptedTypes: []string{MediaTypeJWT},
+		strict:        StrictDecoding,
+	}
+	for _, opt := range options {
+		if opt != nil {
+			opt(ret)
+		}
+	}
+	ret.limits = ret.limits.resolve()
+	if ret.codec == nil {
+		ret.codec = GetCodec()
+	}
+	return ret
+}
+
+// checkState runs the checks that depend on state outside of the token, once the token is valid
+func (o *parseOptions) checkState(c *TokenData) error {
+	if o.revocation != nil {
+		if err := c.CheckRevocation(o.revocation); err != nil {
+			return err
+		}
+	}
+	if o.replay != nil {
+		if err := c.CheckReplay(o.replay); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,31 @@
+package jwt
+
+import "sync"
+
+// maxPooledBuffer is the capacity above which buffers are not returned to the pool, so a single
+// large token does not keep a large buffer alive
+const maxPooledBuffer = 16 << 10
+
+var bufferPool = sync.Pool{
+	New: func() interface{} {
+		b := make([]byte, 0, 1024)
+		return &b
+	},
+}
+
+// getBuffer obtains an empty buffer from the pool. The pointer is returned to the pool with
+// `putBuffer`, so releasing the buffer does not allocate.
+func getBuffer() *[]byte {
+	b := bufferPool.Get().(*[]byte)
+	*b = (*b)[:0]
+	return b
+}
+
+// putBuffer returns a buffer obtained with `getBuffer` to the pool
+func putBuffer(b *[]byte) {
+	if b == nil || cap(*b) > maxPooledBuffer {
+		return
+	}
+	*b = (*b)[:0]
+	bufferPool.Put(b)
+}
@@ -0,0 +1,37 @@
+package jwt
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBufferPool(t *testing.T) {
+	if raceEnabled {
+		t.Skip("sync.Pool drops items randomly with the race detector")
+	}
+	// Releasing a buffer reuses its pointer, so a get and put cycle does not allocate
+	assert.Equal(t, 0.0, testing.AllocsPerRun(100, func() {
+		b := getBuffer()
+		*b = append(*b, "header"...)
+		putBuffer(b)
+	}))
+
+	b := getBuffer()
+	assert.Empty(t, *b)
+	*b = make([]byte, maxPooledBuffer+1)
+	putBuffer(b)
+	putBuffer(nil)
+}
+
+func TestParseHeaderBuffer(t *testing.T) {
+	// The header is only read from the pooled buffer while parsing
+	token := signRaw(t, `{"alg":"HS256","typ":"JWT","kid":"k1"}`, `{"sub":"alice"}`)
+	data, err := ParseAndValidate(token, &ExtendedClaims{}, testSecret)
+	assert.Nil(t, err)
+	for i := 0; i < 10; i++ {
+		_, err := ParseAndValidate(signRaw(t, `{"alg":"HS256","typ":"JWT","kid":"other"}`, `{}`), &ExtendedClaims{}, testSecret)
+		assert.Nil(t, err)
+	}
+	assert.Equal(t, "k1", data.Header.KeyID())
+}
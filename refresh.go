@@ -0,0 +1,305 @@
+package jwt
+
+import (
+	"crypto/rand"
+	"sync"
+	"time"
+
+	"github.com/jucardi/go-jwt/encoding"
+	"github.com/jucardi/go-jwt/keys"
+)
+
+const (
+	// FieldTokenFamily is the key in `ExtendedClaims.Fields` holding the family of a refresh token,
+	// shared by every token obtained by rotating the first refresh token of a session. It is only
+	// set in refresh tokens, so the family is not disclosed to the consumers of access tokens.
+	FieldTokenFamily = "family"
+
+	defaultAccessTTL  = 15 * time.Minute
+	defaultRefreshTTL = 30 * 24 * time.Hour
+)
+
+// RefreshStore tracks the families of refresh tokens, so each refresh token can only be exchanged
+// once. Implementations must be safe for concurrent use.
+type RefreshStore interface {
+	// Register starts tracking a new family with its first refresh token
+	Register(family, tokenID string, expiresAt time.Time) error
+	// Rotate replaces the current refresh token of the family with a new one, only if the used
+	// token is the current one and the family is not revoked. Returns false otherwise. Checking
+	// and replacing the token must be atomic.
+	Rotate(family, usedID, newID string, expiresAt time.Time) (bool, error)
+	// RevokeFamily revokes the family, so none of its refresh tokens can be exchanged anymore
+	RevokeFamily(family string) error
+}
+
+// TokenPair contains an access token with the refresh token to obtain a new one
+type TokenPair struct {
+	AccessToken   string          // AccessToken is the signed access token
+	AccessClaims  *ExtendedClaims // AccessClaims are the claims of the access token
+	RefreshToken  string          // RefreshToken is the signed refresh token
+	RefreshClaims *ExtendedClaims // RefreshClaims are the claims of the refresh token
+}
+
+// RefreshIssuer issues access and refresh token pairs, and exchanges refresh tokens for new pairs.
+// Every exchange rotates the refresh token: presenting a refresh token that was already exchanged
+// indicates it was leaked, so its whole family is revoked and the exchange fails with an
+// ErrRefreshReused error.
+type RefreshIssuer struct {
+	// Issuer mints and signs both tokens of the pairs, so its keys, TTLs and sign options apply to
+	// them as to any other token. Its name and audience are required when exchanging refresh tokens.
+	Issuer *Issuer
+	// PublicKey is the key used to validate the refresh tokens. If nil, it is derived from the key
+	// the issuer signs refresh tokens with, which requires that key not to be wrapped.
+	PublicKey interface{}
+	// Store tracks the refresh token families
+	Store RefreshStore
+}
+
+// NewRefreshIssuer creates a new RefreshIssuer
+//
+//   {issuer} - The issuer minting and signing the tokens
+//   {store}  - The store tracking the refresh token families, a new MemoryRefreshStore if nil
+//
+func NewRefreshIssuer(issuer *Issuer, store RefreshStore) *RefreshIssuer {
+	if store == nil {
+		store = NewMemoryRefreshStore()
+	}
+	return &RefreshIssuer{Issuer: issuer, Store: store}
+}
+
+// IssuePair issues a new access and refresh token pair, starting a new refresh token family
+//
+//   {claims} - The claims of the access token, such as the subject and scope. The registered time
+//              claims, "jti" and "type" are set by the issuer, and "iss" and "aud" if configured.
+//
+func (r *RefreshIssuer) IssuePair(claims *ExtendedClaims) (*TokenPair, error) {
+	if claims == nil {
+		return nil, newError(ErrNilToken, "failed to issue tokens, claims are nil")
+	}
+	family := newTokenID()
+	pair, err := r.newPair(claims, family)
+	if err != nil {
+		return nil, err
+	}
+	if err := r.Store.Register(family, pair.RefreshClaims.Id, pair.RefreshClaims.ExpiresAt.Time); err != nil {
+		return nil, newErrorf(ErrRevoked, "failed to register the refresh token, %s", err.Error())
+	}
+	return pair, nil
+}
+
+// Refresh exchanges a refresh token for a new access and refresh token pair. The new access token
+// carries the same claims as the refresh token. If the refresh token was already exchanged, its
+// family is revoked and an ErrRefreshReused error is returned.
+//
+//   {refreshToken} - The refresh token string
+//   {options}      - (optional) Options to customize the parsing of the refresh token
+//
+func (r *RefreshIssuer) Refresh(refreshToken string, options ...ParseOption) (*TokenPair, error) {
+	publicKey, err := r.publicKey()
+	if err != nil {
+		return nil, err
+	}
+	claims := &ExtendedClaims{}
+	if _, err := ParseAndValidate(refreshToken, claims, publicKey, options...); err != nil {
+		return nil, err
+	}
+	if claims.Type != TokenTypeRefresh {
+		return nil, newErrorf(ErrTokenType, "expected a '%s' token, got '%s'", TokenTypeRefresh, claims.Type)
+	}
+	if err := verifyIssuer(r.Issuer.Name, claims.Issuer); err != nil {
+		return nil, err
+	}
+	if err := verifyAudience(r.Issuer.Audience, claims.Audience); err != nil {
+		return nil, err
+	}
+	family, _ := claims.Fields[FieldTokenFamily].(string)
+	if family == "" || claims.Id == "" {
+		return nil, newErrorf(ErrRefreshReused, "the refresh token has no '%s' field or 'jti' claim", FieldTokenFamily)
+	}
+
+	pair, err := r.newPair(claims, family)
+	if err != nil {
+		return nil, err
+	}
+	ok, err := r.Store.Rotate(family, claims.Id, pair.RefreshClaims.Id, pair.RefreshClaims.ExpiresAt.Time)
+	if err != nil {
+		return nil, newErrorf(ErrRevoked, "failed to rotate the refresh token, %s", err.Error())
+	}
+	if !ok {
+		if err := r.Store.RevokeFamily(family); err != nil {
+			return nil, newErrorf(ErrRevoked, "failed to revoke the refresh token family, %s", err.Error())
+		}
+		return nil, newError(ErrRefreshReused, "the refresh token was already used or revoked, its family has been revoked")
+	}
+	return pair, nil
+}
+
+// RevokeFamily revokes the family of the provided refresh token, e.g. on logout, so none of its
+// refresh tokens can be exchanged anymore. Access tokens already issued remain valid until they
+// expire.
+//
+//   {refreshToken} - The refresh token string
+//   {options}      - (optional) Options to customize the parsing of the refresh token
+//
+func (r *RefreshIssuer) RevokeFamily(refreshToken string, options ...ParseOption) error {
+	publicKey, err := r.publicKey()
+	if err != nil {
+		return err
+	}
+	claims := &ExtendedClaims{}
+	if _, err := ParseAndValidate(refreshToken, claims, publicKey, options...); err != nil {
+		return err
+	}
+	family, _ := claims.Fields[FieldTokenFamily].(string)
+	if claims.Type != TokenTypeRefresh || family == "" {
+		return newErrorf(ErrTokenType, "expected a '%s' token", TokenTypeRefresh)
+	}
+	return r.Store.RevokeFamily(family)
+}
+
+// newPair issues a new access and refresh token pair of the provided family
+func (r *RefreshIssuer) newPair(claims *ExtendedClaims, family string) (*TokenPair, error) {
+	if r.Issuer == nil {
+		return nil, newError(ErrInvalidKey, "failed to issue tokens, the refresh issuer has no issuer")
+	}
+	var err error
+	ret := &TokenPair{}
+	template := *claims
+	template.Fields = copyFields(claims.Fields, "")
+	if ret.AccessToken, ret.AccessClaims, err = r.Issuer.IssueToken(TokenTypeAccess, &template); err != nil {
+		return nil, err
+	}
+	template.Fields = copyFields(claims.Fields, family)
+	if ret.RefreshToken, ret.RefreshClaims, err = r.Issuer.IssueToken(TokenTypeRefresh, &template); err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+func (r *RefreshIssuer) publicKey() (interface{}, error) {
+	if r.PublicKey != nil {
+		return r.PublicKey, nil
+	}
+	if r.Issuer == nil {
+		return nil, newError(ErrInvalidKey, "unable to derive the public key, the refresh issuer has no issuer")
+	}
+	key := r.Issuer.signingKey(TokenTypeRefresh).Key
+	if _, ok := key.([]byte); ok {
+		return key, nil
+	}
+	ret, err := keys.PublicKey(key)
+	if err != nil {
+		return nil, newErrorf(ErrInvalidKey, "unable to derive the public key, %s", err.Error())
+	}
+	return ret, nil
+}
+
+// copyFields copies the additional fields of the claims, replacing the token family with the
+// provided one. The family is removed if empty.
+func copyFields(fields map[string]interface{}, family string) map[string]interface{} {
+	ret := make(map[string]interface{}, len(fields)+1)
+	for k, v := range fields {
+		if k != FieldTokenFamily {
+			ret[k] = v
+		}
+	}
+	if family != "" {
+		ret[FieldTokenFamily] = family
+	}
+	if len(ret) == 0 {
+		return nil
+	}
+	return ret
+}
+
+// newTokenID generates a random identifier for the "jti" claim or a token family
+func newTokenID() string {
+	b := make([]byte, 16)
+	// crypto/rand.Read never returns an error, it crashes the program if the system fails to
+	// provide randomness
+	rand.Read(b)
+	return encoding.EncodeSegment(b)
+}
+
+// MemoryRefreshStore is a RefreshStore that keeps the refresh token families in memory. Families
+// are removed once their latest refresh token expires.
+type MemoryRefreshStore struct {
+	mux       sync.Mutex
+	families  map[string]*refreshFamily
+	nextSweep time.Time
+}
+
+type refreshFamily struct {
+	current string
+	expires time.Time
+	revoked bool
+}
+
+// NewMemoryRefreshStore creates a new empty MemoryRefreshStore
+func NewMemoryRefreshStore() *MemoryRefreshStore {
+	return &MemoryRefreshStore{families: map[string]*refreshFamily{}}
+}
+
+// Register starts tracking a new family with its first refresh token
+//
+//   {family}    - The family identifier
+//   {tokenID}   - The "jti" claim of the first refresh token
+//   {expiresAt} - The expiration of the refresh token
+//
+func (m *MemoryRefreshStore) Register(family, tokenID string, expiresAt time.Time) error {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+	if _, ok := m.families[family]; ok {
+		return newErrorf(ErrRefreshReused, "token family '%s' already exists", family)
+	}
+	m.families[family] = &refreshFamily{current: tokenID, expires: expiresAt}
+	m.sweep(time.Now())
+	return nil
+}
+
+// Rotate replaces the current refresh token of the family, only if the used token is the current
+// one and the family is not revoked
+//
+//   {family}    - The family identifier
+//   {usedID}    - The "jti" claim of the refresh token being exchanged
+//   {newID}     - The "jti" claim of the new refresh token
+//   {expiresAt} - The expiration of the new refresh token
+//
+func (m *MemoryRefreshStore) Rotate(family, usedID, newID string, expiresAt time.Time) (bool, error) {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+	f, ok := m.families[family]
+	if !ok || f.revoked || f.current != usedID {
+		return false, nil
+	}
+	f.current = newID
+	f.expires = expiresAt
+	return true, nil
+}
+
+// RevokeFamily revokes the family, so none of its refresh tokens can be exchanged anymore
+//
+//   {family} - The family identifier
+//
+func (m *MemoryRefreshStore) RevokeFamily(family string) error {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+	if f, ok := m.families[family]; ok {
+		f.revoked = true
+	}
+	return nil
+}
+
+// sweep removes the expired families at most once per `revocationSweepInterval`. Must be invoked
+// with the lock held.
+func (m *MemoryRefreshStore) sweep(now time.Time) {
+	if now.Before(m.nextSweep) {
+		return
+	}
+	m.nextSweep = now.Add(revocationSweepInterval)
+	for id, f := range m.families {
+		if !now.Before(f.expires) {
+			delete(m.families, id)
+		}
+	}
+}
@@ -0,0 +1,98 @@
+package jwt
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jucardi/go-jwt/signing"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRefreshIssuerFamily(t *testing.T) {
+	issuer := NewRefreshIssuer(NewIssuer("issuer", testSecret), nil)
+	claims := testClaims()
+	claims.Fields = map[string]interface{}{"tenant": "acme", FieldTokenFamily: "spoofed"}
+
+	pair, err := issuer.IssuePair(claims)
+	assert.Nil(t, err)
+	assert.Equal(t, "spoofed", claims.Fields[FieldTokenFamily], "the provided claims are not modified")
+
+	// Only the refresh token carries the family
+	access := &ExtendedClaims{}
+	_, err = ParseAndValidate(pair.AccessToken, access, testSecret)
+	assert.Nil(t, err)
+	assert.Equal(t, TokenTypeAccess, access.Type)
+	assert.Equal(t, "acme", access.Fields["tenant"])
+	assert.NotContains(t, access.Fields, FieldTokenFamily)
+
+	refresh := &ExtendedClaims{}
+	_, err = ParseAndValidate(pair.RefreshToken, refresh, testSecret)
+	assert.Nil(t, err)
+	assert.Equal(t, TokenTypeRefresh, refresh.Type)
+	assert.Equal(t, "acme", refresh.Fields["tenant"])
+	family := refresh.Fields[FieldTokenFamily]
+	assert.NotEmpty(t, family)
+	assert.NotEqual(t, "spoofed", family)
+
+	// Rotation keeps the family in the refresh token only
+	rotated, err := issuer.Refresh(pair.RefreshToken)
+	assert.Nil(t, err)
+	assert.NotContains(t, rotated.AccessClaims.Fields, FieldTokenFamily)
+	assert.Equal(t, family, rotated.RefreshClaims.Fields[FieldTokenFamily])
+
+	_, err = issuer.Refresh(pair.AccessToken)
+	assert.True(t, ErrTokenType.IsType(err))
+}
+
+func TestRefreshIssuerReuse(t *testing.T) {
+	issuer := NewRefreshIssuer(NewIssuer("issuer", testSecret), nil)
+	pair, err := issuer.IssuePair(testClaims())
+	assert.Nil(t, err)
+
+	rotated, err := issuer.Refresh(pair.RefreshToken)
+	assert.Nil(t, err)
+
+	// Reusing the first refresh token revokes the whole family
+	_, err = issuer.Refresh(pair.RefreshToken)
+	assert.True(t, ErrRefreshReused.IsType(err))
+	_, err = issuer.Refresh(rotated.RefreshToken)
+	assert.True(t, ErrRefreshReused.IsType(err))
+
+	pair, err = issuer.IssuePair(testClaims())
+	assert.Nil(t, err)
+	assert.Nil(t, issuer.RevokeFamily(pair.RefreshToken))
+	_, err = issuer.Refresh(pair.RefreshToken)
+	assert.True(t, ErrRefreshReused.IsType(err))
+}
+
+func TestRefreshIssuerUsesIssuer(t *testing.T) {
+	refreshSecret := append(append([]byte{}, testSecret...), testSecret...)
+	issuer := NewIssuer("issuer", testSecret)
+	issuer.Key.KeyID = "hs-1"
+	issuer.KeysByType = map[TokenType]SigningKey{TokenTypeRefresh: {Key: refreshSecret, Algorithm: signing.AlgorithmHS512, KeyID: "hs-2"}}
+	issuer.TTL = map[TokenType]time.Duration{TokenTypeAccess: 5 * time.Minute}
+	issuer.SignOptions = []SignOption{WithType(MediaTypeAccessToken)}
+	r := NewRefreshIssuer(issuer, nil)
+
+	pair, err := r.IssuePair(testClaims())
+	assert.Nil(t, err)
+	pair, err = r.Refresh(pair.RefreshToken, WithAcceptedTypes(MediaTypeAccessToken))
+	assert.Nil(t, err)
+	assert.Equal(t, 5*time.Minute, pair.AccessClaims.ExpiresAt.Sub(pair.AccessClaims.IssuedAt.Time))
+
+	// Rotated tokens keep the key id, algorithm and type configured in the issuer
+	data, err := ParseAndValidate(pair.AccessToken, &ExtendedClaims{}, testSecret, WithAcceptedTypes(MediaTypeAccessToken))
+	assert.Nil(t, err)
+	assert.Equal(t, "hs-1", data.Header.KeyID())
+	assert.Equal(t, signing.AlgorithmHS256, data.Header.Algorithm())
+	assert.Equal(t, MediaTypeAccessToken, data.Header.Type())
+
+	data, err = ParseAndValidate(pair.RefreshToken, &ExtendedClaims{}, refreshSecret, WithAcceptedTypes(MediaTypeAccessToken))
+	assert.Nil(t, err)
+	assert.Equal(t, "hs-2", data.Header.KeyID())
+	assert.Equal(t, signing.AlgorithmHS512, data.Header.Algorithm())
+	assert.Equal(t, MediaTypeAccessToken, data.Header.Type())
+
+	_, err = NewRefreshIssuer(nil, nil).IssuePair(testClaims())
+	assert.True(t, ErrInvalidKey.IsType(err))
+}
@@ -0,0 +1,160 @@
+package jwt
+
+import (
+	"fmt"
+	"hash/maphash"
+	"sync"
+	"time"
+)
+
+const (
+	defaultReplayShards = 64
+	replaySweepInterval = time.Minute
+)
+
+// ReplayStore records the single-use tokens already presented. Implementations must be safe for
+// concurrent use.
+type ReplayStore interface {
+	// Use records the provided key until it expires, and returns false if the key was already
+	// recorded and did not expire yet. Recording and checking must be atomic, so only one of
+	// concurrent calls with the same key succeeds.
+	Use(key string, expiresAt time.Time) (bool, error)
+}
+
+// ReplayGuard rejects the second presentation of single-use tokens, such as email links or client
+// assertions, by recording their "jti" claim scoped by their "iss" claim until they expire. Tokens
+// without "jti" or "exp" claims are rejected since they cannot be tracked.
+type ReplayGuard struct {
+	// Store is where the presented tokens are recorded
+	Store ReplayStore
+	// Leeway is added to the expiration of the tokens when recording them, to account for clock
+	// skew between the issuer and the validators
+	Leeway time.Duration
+}
+
+// NewReplayGuard creates a new ReplayGuard
+//
+//   {store}  - The store where the presented tokens are recorded, a new MemoryReplayStore if nil
+//   {leeway} - (optional) Time added to the expiration of the tokens when recording them
+//
+func NewReplayGuard(store ReplayStore, leeway ...time.Duration) *ReplayGuard {
+	if store == nil {
+		store = NewMemoryReplayStore()
+	}
+	ret := &ReplayGuard{Store: store}
+	if len(leeway) > 0 {
+		ret.Leeway = leeway[0]
+	}
+	return ret
+}
+
+// CheckReplay records the token in the provided guard and returns an ErrReplayed error if it was
+// already presented. The token should be fully validated before, so invalid presentations do not
+// consume the token.
+//
+//   {guard} - The replay guard
+//
+func (c *TokenData) CheckReplay(guard *ReplayGuard) error {
+	if c == nil {
+		return newError(ErrNilToken, "failed to check replay, token is nil")
+	}
+	if guard == nil || guard.Store == nil {
+		return newError(ErrReplayed, "a replay store is required to check replay")
+	}
+	claims, err := c.registered()
+	if err != nil {
+		return newErrorf(ErrReplayed, "unable to verify the token was not replayed, %s", err.Error())
+	}
+	if claims.Id == "" {
+		return newError(ErrReplayed, "single-use tokens require a 'jti' claim")
+	}
+	if claims.ExpiresAt == nil {
+		return newError(ErrReplayed, "single-use tokens require an 'exp' claim")
+	}
+	// The issuer is length prefixed so different issuer and id pairs never produce the same key
+	key := fmt.Sprintf("%d:%s%s", len(claims.Issuer), claims.Issuer, claims.Id)
+	ok, err := guard.Store.Use(key, claims.ExpiresAt.Add(guard.Leeway))
+	if err != nil {
+		return newErrorf(ErrReplayed, "unable to verify the token was not replayed, %s", err.Error())
+	}
+	if !ok {
+		return newError(ErrReplayed, "token has already been used")
+	}
+	return nil
+}
+
+// MemoryReplayStore is a ReplayStore that keeps the presented tokens in memory. Keys are spread
+// across shards with their own locks, to reduce contention.
+type MemoryReplayStore struct {
+	seed   maphash.Seed
+	shards []replayShard
+}
+
+type replayShard struct {
+	mux       sync.Mutex
+	entries   map[string]time.Time
+	nextSweep time.Time
+}
+
+// NewMemoryReplayStore creates a new empty MemoryReplayStore
+//
+//   {shards} - (optional) The number of shards, 64 by default
+//
+func NewMemoryReplayStore(shards ...int) *MemoryReplayStore {
+	n := defaultReplayShards
+	if len(shards) > 0 && shards[0] > 0 {
+		n = shards[0]
+	}
+	ret := &MemoryReplayStore{
+		seed:   maphash.MakeSeed(),
+		shards: make([]replayShard, n),
+	}
+	for i := range ret.shards {
+		ret.shards[i].entries = map[string]time.Time{}
+	}
+	return ret
+}
+
+// Use records the provided key until it expires, and returns false if it was already recorded
+//
+//   {key}       - The key identifying the token
+//   {expiresAt} - When the key can be forgotten
+//
+func (m *MemoryReplayStore) Use(key string, expiresAt time.Time) (bool, error) {
+	shard := &m.shards[maphash.String(m.seed, key)%uint64(len(m.shards))]
+	now := time.Now()
+
+	shard.mux.Lock()
+	defer shard.mux.Unlock()
+	if expires, ok := shard.entries[key]; ok && now.Before(expires) {
+		return false, nil
+	}
+	shard.entries[key] = expiresAt
+	shard.sweep(now)
+	return true, nil
+}
+
+// Len returns the number of recorded keys, including the expired ones not removed yet
+func (m *MemoryReplayStore) Len() int {
+	ret := 0
+	for i := range m.shards {
+		m.shards[i].mux.Lock()
+		ret += len(m.shards[i].entries)
+		m.shards[i].mux.Unlock()
+	}
+	return ret
+}
+
+// sweep removes the expired keys at most once per `replaySweepInterval`. Must be invoked with the
+// lock held.
+func (s *replayShard) sweep(now time.Time) {
+	if now.Before(s.nextSweep) {
+		return
+	}
+	s.nextSweep = now.Add(replaySweepInterval)
+	for key, expires := range s.entries {
+		if !now.Before(expires) {
+			delete(s.entries, key)
+		}
+	}
+}
@@ -0,0 +1,136 @@
+package jwt
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// replayStoreFunc allows a function to be used as a ReplayStore
+type replayStoreFunc func(key string, expiresAt time.Time) (bool, error)
+
+func (f replayStoreFunc) Use(key string, expiresAt time.Time) (bool, error) {
+	return f(key, expiresAt)
+}
+
+func parseReplayToken(t *testing.T, claims *ExtendedClaims) *TokenData {
+	token, err := Sign(claims, testSecret)
+	assert.Nil(t, err)
+	data, err := ParseAndValidate(token, &ExtendedClaims{}, testSecret)
+	assert.Nil(t, err)
+	return data
+}
+
+func TestCheckReplay(t *testing.T) {
+	guard := NewReplayGuard(nil)
+	data := parseReplayToken(t, testClaims())
+	assert.Nil(t, data.CheckReplay(guard))
+	assert.True(t, ErrReplayed.IsType(data.CheckReplay(guard)))
+
+	// Presenting the token again, even parsed anew, is a replay
+	assert.True(t, ErrReplayed.IsType(parseReplayToken(t, testClaims()).CheckReplay(guard)))
+
+	// Only one of concurrent presentations succeeds
+	claims := testClaims()
+	claims.Id = "concurrent"
+	data = parseReplayToken(t, claims)
+	var (
+		wg       sync.WaitGroup
+		accepted atomic.Int64
+	)
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if data.CheckReplay(guard) == nil {
+				accepted.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, int64(1), accepted.Load())
+
+	assert.True(t, ErrReplayed.IsType(data.CheckReplay(nil)))
+	assert.True(t, ErrNilToken.IsType((*TokenData)(nil).CheckReplay(guard)))
+}
+
+func TestCheckReplayRequiredClaims(t *testing.T) {
+	guard := NewReplayGuard(nil)
+
+	claims := testClaims()
+	claims.Id = ""
+	err := parseReplayToken(t, claims).CheckReplay(guard)
+	assert.True(t, ErrReplayed.IsType(err))
+	assert.Contains(t, err.Error(), "'jti'")
+
+	claims = testClaims()
+	claims.ExpiresAt = nil
+	err = parseReplayToken(t, claims).CheckReplay(guard)
+	assert.True(t, ErrReplayed.IsType(err))
+	assert.Contains(t, err.Error(), "'exp'")
+	assert.Equal(t, 0, guard.Store.(*MemoryReplayStore).Len())
+}
+
+func TestCheckReplayIssuerScope(t *testing.T) {
+	guard := NewReplayGuard(nil)
+	for _, iss := range []string{"issuer", "other", ""} {
+		claims := testClaims()
+		claims.Issuer = iss
+		assert.Nil(t, parseReplayToken(t, claims).CheckReplay(guard), iss)
+	}
+
+	// The issuer and id are not simply concatenated
+	first, second := testClaims(), testClaims()
+	first.Issuer, first.Id = "a", "bc"
+	second.Issuer, second.Id = "ab", "c"
+	assert.Nil(t, parseReplayToken(t, first).CheckReplay(guard))
+	assert.Nil(t, parseReplayToken(t, second).CheckReplay(guard))
+}
+
+func TestCheckReplayStore(t *testing.T) {
+	var recorded time.Time
+	guard := NewReplayGuard(replayStoreFunc(func(_ string, expiresAt time.Time) (bool, error) {
+		recorded = expiresAt
+		return true, nil
+	}), time.Minute)
+
+	// The token is recorded until it expires, plus the leeway
+	data := parseReplayToken(t, testClaims())
+	assert.Nil(t, data.CheckReplay(guard))
+	claims, _ := data.registered()
+	assert.True(t, recorded.Equal(claims.ExpiresAt.Add(time.Minute)))
+
+	guard.Store = replayStoreFunc(func(string, time.Time) (bool, error) {
+		return false, errors.New("unavailable")
+	})
+	err := data.CheckReplay(guard)
+	assert.True(t, ErrReplayed.IsType(err))
+	assert.Contains(t, err.Error(), "unavailable")
+}
+
+func TestMemoryReplayStoreExpiration(t *testing.T) {
+	store := NewMemoryReplayStore(1)
+	ok, err := store.Use("key", time.Now().Add(20*time.Millisecond))
+	assert.Nil(t, err)
+	assert.True(t, ok)
+	ok, _ = store.Use("key", time.Now().Add(20*time.Millisecond))
+	assert.False(t, ok)
+
+	// Once expired, the key can be used again
+	time.Sleep(30 * time.Millisecond)
+	ok, _ = store.Use("key", time.Now().Add(time.Hour))
+	assert.True(t, ok)
+
+	// Expired keys are removed on the next sweep
+	_, _ = store.Use("expired", time.Now().Add(-time.Second))
+	assert.Equal(t, 2, store.Len())
+	store.shards[0].nextSweep = time.Time{}
+	_, _ = store.Use("other", time.Now().Add(time.Hour))
+	assert.Equal(t, 2, store.Len())
+	ok, _ = store.Use("expired", time.Now().Add(time.Hour))
+	assert.True(t, ok)
+}
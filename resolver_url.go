@@ -0,0 +1,307 @@
+package jwt
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jucardi/go-jwt/keys"
+)
+
+const (
+	defaultURLTimeout         = 5 * time.Second
+	defaultURLMaxResponseSize = 1 << 20
+	defaultURLCacheTTL        = 10 * time.Minute
+	failedURLCacheTTL         = 30 * time.Second
+	maxURLCacheEntries        = 100
+)
+
+// AllowedURL defines a location the "jku" and "x5u" headers are allowed to point to
+type AllowedURL struct {
+	// Scheme is the required URL scheme, "https" if empty
+	Scheme string
+	// Host is the required host, including the port if not the default one for the scheme
+	Host string
+	// PathPrefix is the path the URL must be under. The match is done on full path segments, so
+	// "/keys" matches "/keys" and "/keys/set.json" but not "/keys-other"
+	PathPrefix string
+}
+
+// URLKeyResolver is a KeyResolver that follows the "jku" (JWK Set URL) and "x5u" (X.509 URL)
+// headers to obtain the key to validate a token, only if the URL matches one of the allowed
+// locations. Redirects are only followed to allowed locations, and the fetched keys are cached.
+//
+// The allow-list is the only trust anchor for the fetched keys, only allow locations controlled
+// by trusted parties. URLs with a query string are never allowed, and failed fetches are cached
+// briefly, so unauthenticated tokens cannot trigger an unbounded number of requests.
+type URLKeyResolver struct {
+	// Allowed is the list of locations keys can be fetched from
+	Allowed []AllowedURL
+	// Client is the HTTP client used to fetch the keys. If nil, a client with `Timeout` is used.
+	Client *http.Client
+	// Timeout is the maximum time a request may take, 5 seconds if zero
+	Timeout time.Duration
+	// MaxResponseSize is the maximum size in bytes of a response, 1 MiB if zero
+	MaxResponseSize int64
+	// CacheTTL indicates how long fetched keys are cached, 10 minutes if zero
+	CacheTTL time.Duration
+
+	mux   sync.Mutex
+	cache map[string]*urlCacheEntry
+}
+
+type urlCacheEntry struct {
+	done    chan struct{} // closed once the fetch completes, concurrent fetches wait on it
+	set     *keys.JWKSet
+	key     interface{}
+	err     error
+	expires time.Time
+}
+
+// completed indicates whether the fetch of the entry completed. The other fields must only be read
+// once it did.
+func (e *urlCacheEntry) completed() bool {
+	select {
+	case <-e.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// NewURLKeyResolver creates a new URLKeyResolver that follows the "jku" and "x5u" headers only to
+// the provided locations
+//
+//   {allowed} - The locations keys are allowed to be fetched from
+//
+func NewURLKeyResolver(allowed ...AllowedURL) *URLKeyResolver {
+	return &URLKeyResolver{Allowed: allowed}
+}
+
+// ResolveKey resolves the key to validate the token from the "jku" header, selecting the key by
+// the "kid" header, or otherwise from the leaf certificate of the "x5u" header
+func (r *URLKeyResolver) ResolveKey(header TokenHeader) (interface{}, error) {
+	if jku := header.JWKSetURL(); jku != "" {
+		entry, err := r.fetch(jku, headerJKUKey)
+		if err != nil {
+			return nil, err
+		}
+		jwk := entry.set.Find(header.KeyID())
+		if jwk == nil {
+			return nil, newErrorf(ErrKeyResolution, "no key with kid '%s' found in '%s'", header.KeyID(), jku)
+		}
+		if jwk.Use != "" && jwk.Use != "sig" {
+			return nil, newErrorf(ErrKeyResolution, "key '%s' is not a signing key", jwk.KeyID)
+		}
+		if jwk.Algorithm != "" && jwk.Algorithm != header.Algorithm().String() {
+			return nil, newErrorf(ErrKeyResolution, "key '%s' is bound to algorithm '%s'", jwk.KeyID, jwk.Algorithm)
+		}
+		if jwk.IsPrivate() {
+			if jwk, err = jwk.Public(); err != nil {
+				return nil, newErrorf(ErrKeyResolution, "invalid key in '%s', %s", jku, err.Error())
+			}
+		}
+		key, err := jwk.Key()
+		if err != nil {
+			return nil, newErrorf(ErrKeyResolution, "invalid key in '%s', %s", jku, err.Error())
+		}
+		return key, nil
+	}
+	if x5u := header.CertificateURL(); x5u != "" {
+		entry, err := r.fetch(x5u, headerX5UKey)
+		if err != nil {
+			return nil, err
+		}
+		return entry.key, nil
+	}
+	return nil, newErrorf(ErrKeyResolution, "the token has no '%s' or '%s' header", headerJKUKey, headerX5UKey)
+}
+
+// IsAllowed indicates whether the provided URL matches one of the allowed locations
+//
+//   {rawURL} - The URL to check
+//
+func (r *URLKeyResolver) IsAllowed(rawURL string) bool {
+	_, ok := r.normalize(rawURL)
+	return ok
+}
+
+// normalize returns the normalized form of the provided URL, which is the URL fetched and the cache
+// key, and whether the URL matches one of the allowed locations
+func (r *URLKeyResolver) normalize(rawURL string) (*url.URL, bool) {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.User != nil || u.Opaque != "" || strings.ContainsRune(rawURL, '#') || u.RawQuery != "" || u.ForceQuery {
+		return nil, false
+	}
+	// Escaped delimiters are rejected, the server could decode them into a query, a fragment or
+	// different path segments than the ones checked
+	if strings.ContainsAny(u.Path, "?#") || strings.Contains(strings.ToLower(u.RawPath), "%2f") {
+		return nil, false
+	}
+	p := u.Path
+	if p == "" {
+		p = "/"
+	}
+	if path.Clean(p) != p && path.Clean(p)+"/" != p {
+		return nil, false
+	}
+	for _, a := range r.Allowed {
+		scheme := a.Scheme
+		if scheme == "" {
+			scheme = "https"
+		}
+		if !strings.EqualFold(u.Scheme, scheme) || !strings.EqualFold(u.Host, a.Host) {
+			continue
+		}
+		prefix := strings.TrimSuffix(a.PathPrefix, "/")
+		if prefix == "" || p == prefix || strings.HasPrefix(p, prefix+"/") {
+			return &url.URL{Scheme: strings.ToLower(u.Scheme), Host: strings.ToLower(u.Host), Path: p, RawPath: u.RawPath}, true
+		}
+	}
+	return nil, false
+}
+
+// fetch obtains the keys of the URL from the cache, or fetches them. Concurrent fetches of the same
+// URL are collapsed into a single request.
+func (r *URLKeyResolver) fetch(rawURL, header string) (*urlCacheEntry, error) {
+	normalized, ok := r.normalize(rawURL)
+	if !ok {
+		return nil, newErrorf(ErrKeyResolution, "the '%s' header URL '%s' is not allowed", header, rawURL)
+	}
+	cacheKey := header + " " + normalized.String()
+
+	r.mux.Lock()
+	entry, ok := r.cache[cacheKey]
+	if ok && (!entry.completed() || time.Now().Before(entry.expires)) {
+		r.mux.Unlock()
+		<-entry.done
+	} else {
+		entry = &urlCacheEntry{done: make(chan struct{})}
+		if r.cache == nil {
+			r.cache = map[string]*urlCacheEntry{}
+		}
+		if len(r.cache) >= maxURLCacheEntries {
+			r.evict()
+		}
+		r.cache[cacheKey] = entry
+		r.mux.Unlock()
+		r.load(entry, normalized, header)
+	}
+
+	if entry.err != nil {
+		return nil, entry.err
+	}
+	return entry, nil
+}
+
+// load fetches the keys of the URL into the entry and marks it as completed
+func (r *URLKeyResolver) load(entry *urlCacheEntry, u *url.URL, header string) {
+	var (
+		set *keys.JWKSet
+		key interface{}
+	)
+	rawURL := u.String()
+	data, err := r.get(u)
+	if err != nil {
+		err = newErrorf(ErrKeyResolution, "failed to fetch '%s', %s", rawURL, err.Error())
+	} else if header == headerJKUKey {
+		if set, err = keys.ParseJWKSet(data); err != nil {
+			err = newErrorf(ErrKeyResolution, "invalid JWK Set in '%s', %s", rawURL, err.Error())
+		}
+	} else if cert, certErr := keys.ParseCertificate(data); certErr != nil {
+		err = newErrorf(ErrKeyResolution, "invalid certificate in '%s', %s", rawURL, certErr.Error())
+	} else {
+		key = cert.PublicKey
+	}
+
+	ttl := valueOrDefault(r.CacheTTL, defaultURLCacheTTL)
+	if err != nil {
+		ttl = failedURLCacheTTL
+	}
+	r.mux.Lock()
+	entry.set, entry.key, entry.err = set, key, err
+	entry.expires = time.Now().Add(ttl)
+	close(entry.done)
+	r.mux.Unlock()
+}
+
+// evict removes the expired and failed entries from the cache, or otherwise the entry expiring
+// first. Entries being fetched are kept. Must be invoked with the lock held.
+func (r *URLKeyResolver) evict() {
+	now := time.Now()
+	var (
+		oldest    string
+		oldestExp time.Time
+	)
+	for k, v := range r.cache {
+		if !v.completed() {
+			continue
+		}
+		if v.err != nil || !now.Before(v.expires) {
+			delete(r.cache, k)
+			continue
+		}
+		if oldest == "" || v.expires.Before(oldestExp) {
+			oldest, oldestExp = k, v.expires
+		}
+	}
+	if len(r.cache) >= maxURLCacheEntries && oldest != "" {
+		delete(r.cache, oldest)
+	}
+}
+
+// get fetches the provided URL, which must be the URL validated against the allow-list and not a
+// string parsed again
+func (r *URLKeyResolver) get(u *url.URL) ([]byte, error) {
+	client := r.Client
+	if client == nil {
+		client = &http.Client{}
+	}
+	// Copy the client so redirects are also checked against the allow-list and the timeout applies
+	c := *client
+	c.Timeout = valueOrDefault(r.Timeout, defaultURLTimeout)
+	c.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		if len(via) >= 5 {
+			return errors.New("too many redirects")
+		}
+		if !r.IsAllowed(req.URL.String()) {
+			return fmt.Errorf("redirect to '%s' is not allowed", req.URL)
+		}
+		return nil
+	}
+
+	resp, err := c.Do(&http.Request{Method: http.MethodGet, URL: u, Header: http.Header{}})
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+
+	max := r.MaxResponseSize
+	if max <= 0 {
+		max = defaultURLMaxResponseSize
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, max+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > max {
+		return nil, fmt.Errorf("response exceeds the maximum size of %d bytes", max)
+	}
+	return data, nil
+}
+
+func valueOrDefault(val, def time.Duration) time.Duration {
+	if val > 0 {
+		return val
+	}
+	return def
+}
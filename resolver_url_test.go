@@ -0,0 +1,236 @@
+package jwt
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/jucardi/go-jwt/keys"
+	"github.com/stretchr/testify/assert"
+)
+
+type testKeyServer struct {
+	*httptest.Server
+	key      *ecdsa.PrivateKey
+	requests atomic.Int64
+	release  chan struct{}
+	redirect string // target of "/keys/external.json"
+
+	mux      sync.Mutex
+	rawPaths []string
+}
+
+// paths returns the escaped paths requested to the server
+func (s *testKeyServer) paths() []string {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	return append([]string{}, s.rawPaths...)
+}
+
+// newTestKeyServer starts a TLS server serving a JWK Set with the key "k1" under "/keys"
+func newTestKeyServer(t *testing.T) *testKeyServer {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.Nil(t, err)
+	jwk, err := keys.ExportJWK(&key.PublicKey)
+	assert.Nil(t, err)
+	jwk.KeyID = "k1"
+	set, _ := json.Marshal(&keys.JWKSet{Keys: []*keys.JWK{jwk}})
+	cert, _ := newTestCertificate(t, "x5u", time.Now().Add(-time.Hour), time.Now().Add(time.Hour), nil, nil)
+
+	ret := &testKeyServer{key: key, release: make(chan struct{})}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/keys/jwks.json", func(w http.ResponseWriter, _ *http.Request) {
+		w.Write(set)
+	})
+	mux.HandleFunc("/keys/cert.pem", func(w http.ResponseWriter, _ *http.Request) {
+		pem.Encode(w, &pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw})
+	})
+	mux.HandleFunc("/keys/large.json", func(w http.ResponseWriter, _ *http.Request) {
+		w.Write([]byte(strings.Repeat(" ", 2048)))
+	})
+	mux.HandleFunc("/keys/slow.json", func(w http.ResponseWriter, _ *http.Request) {
+		<-ret.release
+	})
+	mux.HandleFunc("/keys/redirect.json", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/keys/jwks.json", http.StatusFound)
+	})
+	mux.HandleFunc("/keys/escape.json", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/other/jwks.json", http.StatusFound)
+	})
+	mux.HandleFunc("/keys/external.json", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, ret.redirect, http.StatusFound)
+	})
+	mux.HandleFunc("/other/jwks.json", func(w http.ResponseWriter, _ *http.Request) {
+		w.Write(set)
+	})
+	ret.Server = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ret.requests.Add(1)
+		ret.mux.Lock()
+		ret.rawPaths = append(ret.rawPaths, r.URL.EscapedPath())
+		ret.mux.Unlock()
+		mux.ServeHTTP(w, r)
+	}))
+	t.Cleanup(func() {
+		close(ret.release)
+		ret.Close()
+	})
+	return ret
+}
+
+func (s *testKeyServer) resolver() *URLKeyResolver {
+	ret := NewURLKeyResolver(AllowedURL{Host: s.Listener.Addr().String(), PathPrefix: "/keys"})
+	ret.Client = s.Client()
+	return ret
+}
+
+func (s *testKeyServer) sign(t *testing.T, jku string) string {
+	data := &TokenData{Header: TokenHeader{}, Token: &ExtendedClaims{Subject: "alice"}}
+	data.Header.SetJWKSetURL(jku)
+	data.Header.SetKeyID("k1")
+	token, err := data.Sign(s.key)
+	assert.Nil(t, err)
+	return token
+}
+
+func TestURLKeyResolverIsAllowed(t *testing.T) {
+	r := NewURLKeyResolver(AllowedURL{Host: "keys.example.com", PathPrefix: "/keys"})
+	for rawURL, allowed := range map[string]bool{
+		"https://keys.example.com/keys":               true,
+		"https://keys.example.com/keys/jwks.json":     true,
+		"https://KEYS.example.com/keys/jwks.json":     true,
+		"http://keys.example.com/keys/jwks.json":      false,
+		"https://keys.example.com/keys-other":         false,
+		"https://keys.example.com/keys/../admin":      false,
+		"https://keys.example.com/keys/jwks.json?n=1": false,
+		"https://keys.example.com/keys/jwks.json?":    false,
+		"https://keys.example.com/keys/jwks.json#k":   false,
+		"https://keys.example.com/keys/jwks.json#":    false,
+		"https://keys.example.com/keys/jwks%2Ejson":   true,
+		"https://keys.example.com/keys/a%3Fx=1":       false,
+		"https://keys.example.com/keys/a%3fx=1":       false,
+		"https://keys.example.com/keys/a%23k":         false,
+		"https://keys.example.com/keys/%2e%2e/admin":  false,
+		"https://keys.example.com/keys/%2E%2E/admin":  false,
+		"https://keys.example.com/keys%2F..%2Fadmin":  false,
+		"https://keys.example.com/keys%2fjwks.json":   false,
+		"https://user@keys.example.com/keys":          false,
+		"https://keys.example.com:8443/keys":          false,
+		"https://other.example.com/keys/jwks.json":    false,
+	} {
+		assert.Equal(t, allowed, r.IsAllowed(rawURL), rawURL)
+	}
+}
+
+func TestURLKeyResolverJWKSet(t *testing.T) {
+	srv := newTestKeyServer(t)
+	r := srv.resolver()
+	token := srv.sign(t, srv.URL+"/keys/jwks.json")
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := ParseAndValidate(token, &ExtendedClaims{}, r)
+			assert.Nil(t, err)
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, int64(1), srv.requests.Load())
+
+	// The cache is keyed on the normalized URL
+	_, err := ParseAndValidate(srv.sign(t, strings.Replace(srv.URL, "https", "HTTPS", 1)+"/keys/jwks.json"), &ExtendedClaims{}, r)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1), srv.requests.Load())
+}
+
+func TestURLKeyResolverCertificate(t *testing.T) {
+	srv := newTestKeyServer(t)
+	header := TokenHeader{}
+	header.SetCertificateURL(srv.URL + "/keys/cert.pem")
+	key, err := srv.resolver().ResolveKey(header)
+	assert.Nil(t, err)
+	assert.IsType(t, &ecdsa.PublicKey{}, key)
+}
+
+func TestURLKeyResolverRejected(t *testing.T) {
+	srv := newTestKeyServer(t)
+	r := srv.resolver()
+	for _, jku := range []string{
+		srv.URL + "/other/jwks.json",
+		srv.URL + "/keys/jwks.json?n=1",
+		srv.URL + "/keys/jwks.json%3Fn=1",
+		srv.URL + "/keys/jwks.json%23k",
+		srv.URL + "/keys/%2e%2e/other/jwks.json",
+	} {
+		_, err := ParseAndValidate(srv.sign(t, jku), &ExtendedClaims{}, r)
+		assert.True(t, ErrKeyResolution.IsType(err), jku)
+	}
+	assert.Equal(t, int64(0), srv.requests.Load())
+}
+
+func TestURLKeyResolverFailuresCached(t *testing.T) {
+	srv := newTestKeyServer(t)
+	r := srv.resolver()
+	token := srv.sign(t, srv.URL+"/keys/missing.json")
+	for i := 0; i < 3; i++ {
+		_, err := ParseAndValidate(token, &ExtendedClaims{}, r)
+		assert.True(t, ErrKeyResolution.IsType(err))
+	}
+	assert.Equal(t, int64(1), srv.requests.Load())
+}
+
+func TestURLKeyResolverRedirects(t *testing.T) {
+	srv := newTestKeyServer(t)
+	r := srv.resolver()
+
+	_, err := ParseAndValidate(srv.sign(t, srv.URL+"/keys/redirect.json"), &ExtendedClaims{}, r)
+	assert.Nil(t, err)
+
+	_, err = ParseAndValidate(srv.sign(t, srv.URL+"/keys/escape.json"), &ExtendedClaims{}, r)
+	assert.True(t, ErrKeyResolution.IsType(err))
+	assert.Contains(t, err.Error(), "is not allowed")
+
+	// A redirect to another host is not followed
+	other := newTestKeyServer(t)
+	srv.redirect = other.URL + "/keys/jwks.json"
+	_, err = ParseAndValidate(srv.sign(t, srv.URL+"/keys/external.json"), &ExtendedClaims{}, r)
+	assert.True(t, ErrKeyResolution.IsType(err))
+	assert.Contains(t, err.Error(), "is not allowed")
+	assert.Equal(t, int64(0), other.requests.Load())
+}
+
+func TestURLKeyResolverFetchesValidatedURL(t *testing.T) {
+	srv := newTestKeyServer(t)
+	r := srv.resolver()
+
+	// The escaped path is requested as validated, not decoded into another path
+	_, err := ParseAndValidate(srv.sign(t, srv.URL+"/keys/jwks%2Ejson"), &ExtendedClaims{}, r)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"/keys/jwks%2Ejson"}, srv.paths())
+}
+
+func TestURLKeyResolverLimits(t *testing.T) {
+	srv := newTestKeyServer(t)
+	r := srv.resolver()
+	r.MaxResponseSize = 1024
+	r.Timeout = 50 * time.Millisecond
+
+	_, err := ParseAndValidate(srv.sign(t, srv.URL+"/keys/large.json"), &ExtendedClaims{}, r)
+	assert.True(t, ErrKeyResolution.IsType(err))
+	assert.Contains(t, err.Error(), "maximum size")
+
+	start := time.Now()
+	_, err = ParseAndValidate(srv.sign(t, srv.URL+"/keys/slow.json"), &ExtendedClaims{}, r)
+	assert.True(t, ErrKeyResolution.IsType(err))
+	assert.Less(t, time.Since(start), 5*time.Second)
+}
@@ -0,0 +1,186 @@
+package jwt
+
+import (
+	"sync"
+	"time"
+)
+
+// revocationSweepInterval is how often the in-memory store removes the expired revocations
+const revocationSweepInterval = time.Minute
+
+// RevocationStore keeps track of the tokens revoked before their expiration. Implementations must
+// be safe for concurrent use.
+type RevocationStore interface {
+	// RevokeID revokes the token with the provided "jti" claim. The revocation may be discarded
+	// once the token expires, a zero expiration keeps it forever.
+	RevokeID(id string, expiresAt time.Time) error
+	// RevokeSubject revokes every token with the provided "sub" claim, including the ones issued
+	// after the revocation
+	RevokeSubject(subject string) error
+	// RevokeIssuedBefore revokes the tokens with the provided "sub" claim issued before the cutoff.
+	// Tokens without an "iat" claim are considered to be issued before any cutoff.
+	RevokeIssuedBefore(subject string, cutoff time.Time) error
+	// IsRevoked indicates whether a token with the provided "jti", "sub" and "iat" claims is revoked.
+	// Empty values indicate the claim is not present.
+	IsRevoked(id, subject string, issuedAt time.Time) (bool, error)
+}
+
+// MemoryRevocationStore is a RevocationStore that keeps the revocations in memory. Revocations by
+// "jti" are removed once the token expires.
+type MemoryRevocationStore struct {
+	mux       sync.RWMutex
+	ids       map[string]time.Time
+	subjects  map[string]struct{}
+	cutoffs   map[string]time.Time
+	nextSweep time.Time
+}
+
+// NewMemoryRevocationStore creates a new empty MemoryRevocationStore
+func NewMemoryRevocationStore() *MemoryRevocationStore {
+	return &MemoryRevocationStore{
+		ids:      map[string]time.Time{},
+		subjects: map[string]struct{}{},
+		cutoffs:  map[string]time.Time{},
+	}
+}
+
+// RevokeID revokes the token with the provided "jti" claim until it expires
+//
+//   {id}        - The "jti" claim of the token
+//   {expiresAt} - The "exp" claim of the token, zero to keep the revocation forever
+//
+func (m *MemoryRevocationStore) RevokeID(id string, expiresAt time.Time) error {
+	if id == "" {
+		return newError(ErrRevoked, "unable to revoke a token without 'jti' claim")
+	}
+	now := time.Now()
+	if !expiresAt.IsZero() && !expiresAt.After(now) {
+		return nil
+	}
+	m.mux.Lock()
+	defer m.mux.Unlock()
+	// Keep the latest expiration if the token was already revoked, zero meaning forever
+	if current, ok := m.ids[id]; !ok || !current.IsZero() && (expiresAt.IsZero() || expiresAt.After(current)) {
+		m.ids[id] = expiresAt
+	}
+	m.sweep(now)
+	return nil
+}
+
+// RevokeSubject revokes every token with the provided "sub" claim
+//
+//   {subject} - The "sub" claim of the tokens
+//
+func (m *MemoryRevocationStore) RevokeSubject(subject string) error {
+	if subject == "" {
+		return newError(ErrRevoked, "unable to revoke tokens without 'sub' claim")
+	}
+	m.mux.Lock()
+	m.subjects[subject] = struct{}{}
+	m.mux.Unlock()
+	return nil
+}
+
+// RevokeIssuedBefore revokes the tokens with the provided "sub" claim issued before the cutoff. Only
+// the latest cutoff of each subject is kept.
+//
+//   {subject} - The "sub" claim of the tokens
+//   {cutoff}  - The tokens issued before this time are revoked
+//
+func (m *MemoryRevocationStore) RevokeIssuedBefore(subject string, cutoff time.Time) error {
+	if subject == "" {
+		return newError(ErrRevoked, "unable to revoke tokens without 'sub' claim")
+	}
+	m.mux.Lock()
+	if current, ok := m.cutoffs[subject]; !ok || cutoff.After(current) {
+		m.cutoffs[subject] = cutoff
+	}
+	m.mux.Unlock()
+	return nil
+}
+
+// IsRevoked indicates whether a token with the provided claims is revoked
+//
+//   {id}       - The "jti" claim of the token, empty if not present
+//   {subject}  - The "sub" claim of the token, empty if not present
+//   {issuedAt} - The "iat" claim of the token, zero if not present
+//
+func (m *MemoryRevocationStore) IsRevoked(id, subject string, issuedAt time.Time) (bool, error) {
+	m.mux.RLock()
+	defer m.mux.RUnlock()
+	if id != "" {
+		if expires, ok := m.ids[id]; ok && (expires.IsZero() || time.Now().Before(expires)) {
+			return true, nil
+		}
+	}
+	if subject != "" {
+		if _, ok := m.subjects[subject]; ok {
+			return true, nil
+		}
+		if cutoff, ok := m.cutoffs[subject]; ok && (issuedAt.IsZero() || issuedAt.Before(cutoff)) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// sweep removes the expired revocations at most once per `revocationSweepInterval`. Must be invoked
+// with the lock held.
+func (m *MemoryRevocationStore) sweep(now time.Time) {
+	if now.Before(m.nextSweep) {
+		return
+	}
+	m.nextSweep = now.Add(revocationSweepInterval)
+	for id, expires := range m.ids {
+		if !expires.IsZero() && !now.Before(expires) {
+			delete(m.ids, id)
+		}
+	}
+}
+
+// CheckRevocation verifies the token was not revoked in the provided store, using its "jti", "sub"
+// and "iat" claims. Returns an ErrRevoked error if the token is revoked, the claims cannot be read
+// or the store fails.
+//
+//   {store} - The revocation store
+//
+func (c *TokenData) CheckRevocation(store RevocationStore) error {
+	if c == nil {
+		return newError(ErrNilToken, "failed to check revocation, token is nil")
+	}
+	claims, err := c.registered()
+	if err != nil {
+		return newErrorf(ErrRevoked, "unable to verify the token was not revoked, %s", err.Error())
+	}
+	var issuedAt time.Time
+	if claims.IssuedAt != nil {
+		issuedAt = claims.IssuedAt.Time
+	}
+	revoked, err := store.IsRevoked(claims.Id, claims.Subject, issuedAt)
+	if err != nil {
+		return newErrorf(ErrRevoked, "unable to verify the token was not revoked, %s", err.Error())
+	}
+	if revoked {
+		return newError(ErrRevoked, "token has been revoked")
+	}
+	return nil
+}
+
+// Revoke revokes the token by its "jti" claim in the provided store until the token expires
+//
+//   {store} - The revocation store
+//
+func (c *TokenData) Revoke(store RevocationStore) error {
+	if c == nil {
+		return newError(ErrNilToken, "failed to revoke token, token is nil")
+	}
+	claims, err := c.registered()
+	if err != nil {
+		return newErrorf(ErrRevoked, "failed to revoke token, %s", err.Error())
+	}
+	var expiresAt time.Time
+	if claims.ExpiresAt != nil {
+		expiresAt = claims.ExpiresAt.Time
+	}
+	return store.RevokeID(claims.Id, expiresAt)
+}
@@ -0,0 +1,236 @@
+package jwt
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// FileRevocationStore is a RevocationStore that keeps the revocations in memory and persists them
+// to a file, so they survive restarts. Every revocation is appended to the file as a JSON line, and
+// the file is compacted when opened to discard the expired revocations.
+type FileRevocationStore struct {
+	*MemoryRevocationStore
+
+	mux  sync.Mutex
+	path string
+	file *os.File
+}
+
+// revocationRecord is a line of the revocation file
+type revocationRecord struct {
+	Id           string       `json:"jti,omitempty"`
+	Subject      string       `json:"sub,omitempty"`
+	IssuedBefore *NumericDate `json:"before,omitempty"`
+	ExpiresAt    *NumericDate `json:"exp,omitempty"`
+}
+
+// NewFileRevocationStore opens the revocation file at the provided path, creating it if it does not
+// exist, and loads the revocations it contains
+//
+//   {path} - The path of the revocation file
+//
+func NewFileRevocationStore(path string) (*FileRevocationStore, error) {
+	ret := &FileRevocationStore{
+		MemoryRevocationStore: NewMemoryRevocationStore(),
+		path:                  path,
+	}
+	if err := ret.load(); err != nil {
+		return nil, err
+	}
+	if err := ret.Compact(); err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+// RevokeID revokes the token with the provided "jti" claim until it expires
+//
+//   {id}        - The "jti" claim of the token
+//   {expiresAt} - The "exp" claim of the token, zero to keep the revocation forever
+//
+func (f *FileRevocationStore) RevokeID(id string, expiresAt time.Time) error {
+	if id == "" {
+		return newError(ErrRevoked, "unable to revoke a token without 'jti' claim")
+	}
+	// As with the in-memory store, revoking an expired token is a no-op
+	if !expiresAt.IsZero() && !expiresAt.After(time.Now()) {
+		return nil
+	}
+	record := revocationRecord{Id: id}
+	if !expiresAt.IsZero() {
+		record.ExpiresAt = NewNumericDate(expiresAt)
+	}
+	return f.apply(record)
+}
+
+// RevokeSubject revokes every token with the provided "sub" claim
+//
+//   {subject} - The "sub" claim of the tokens
+//
+func (f *FileRevocationStore) RevokeSubject(subject string) error {
+	return f.apply(revocationRecord{Subject: subject})
+}
+
+// RevokeIssuedBefore revokes the tokens with the provided "sub" claim issued before the cutoff
+//
+//   {subject} - The "sub" claim of the tokens
+//   {cutoff}  - The tokens issued before this time are revoked
+//
+func (f *FileRevocationStore) RevokeIssuedBefore(subject string, cutoff time.Time) error {
+	return f.apply(revocationRecord{Subject: subject, IssuedBefore: NewNumericDate(cutoff)})
+}
+
+// Compact rewrites the revocation file with the current revocations only, discarding the expired
+// ones and the cutoffs superseded by later ones
+func (f *FileRevocationStore) Compact() error {
+	f.mux.Lock()
+	defer f.mux.Unlock()
+
+	tmp, err := os.CreateTemp(filepath.Dir(f.path), filepath.Base(f.path)+".*")
+	if err != nil {
+		return fmt.Errorf("failed to compact revocation file, %s", err.Error())
+	}
+	defer os.Remove(tmp.Name())
+
+	w := bufio.NewWriter(tmp)
+	enc := json.NewEncoder(w)
+	for _, record := range f.records() {
+		if err := enc.Encode(record); err != nil {
+			tmp.Close()
+			return fmt.Errorf("failed to compact revocation file, %s", err.Error())
+		}
+	}
+	err = w.Flush()
+	if err == nil {
+		err = tmp.Sync()
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("failed to compact revocation file, %s", err.Error())
+	}
+	if err := os.Rename(tmp.Name(), f.path); err != nil {
+		return fmt.Errorf("failed to compact revocation file, %s", err.Error())
+	}
+
+	if f.file != nil {
+		f.file.Close()
+	}
+	f.file, err = os.OpenFile(f.path, os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open revocation file, %s", err.Error())
+	}
+	return nil
+}
+
+// Close closes the revocation file. The store must not be used after it is closed.
+func (f *FileRevocationStore) Close() error {
+	f.mux.Lock()
+	defer f.mux.Unlock()
+	if f.file == nil {
+		return nil
+	}
+	err := f.file.Close()
+	f.file = nil
+	return err
+}
+
+// apply stores the revocation in memory and appends it to the file. The lock is held for both so
+// a concurrent compaction cannot lose the revocation.
+func (f *FileRevocationStore) apply(record revocationRecord) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+
+	f.mux.Lock()
+	defer f.mux.Unlock()
+	if f.file == nil {
+		return fmt.Errorf("revocation file '%s' is closed", f.path)
+	}
+	if err := f.applyMemory(record); err != nil {
+		return err
+	}
+	if _, err := f.file.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write revocation file, %s", err.Error())
+	}
+	if err := f.file.Sync(); err != nil {
+		return fmt.Errorf("failed to write revocation file, %s", err.Error())
+	}
+	return nil
+}
+
+func (f *FileRevocationStore) applyMemory(record revocationRecord) error {
+	switch {
+	case record.Id != "":
+		var expiresAt time.Time
+		if record.ExpiresAt != nil {
+			expiresAt = record.ExpiresAt.Time
+		}
+		return f.MemoryRevocationStore.RevokeID(record.Id, expiresAt)
+	case record.IssuedBefore != nil:
+		return f.MemoryRevocationStore.RevokeIssuedBefore(record.Subject, record.IssuedBefore.Time)
+	default:
+		return f.MemoryRevocationStore.RevokeSubject(record.Subject)
+	}
+}
+
+// load replays the revocation file into memory
+func (f *FileRevocationStore) load() error {
+	file, err := os.Open(f.path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to open revocation file, %s", err.Error())
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var record revocationRecord
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			return fmt.Errorf("invalid revocation file, line %d: %s", line, err.Error())
+		}
+		if err := f.applyMemory(record); err != nil {
+			return fmt.Errorf("invalid revocation file, line %d: %s", line, err.Error())
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read revocation file, %s", err.Error())
+	}
+	return nil
+}
+
+// records returns the current revocations of the in-memory store
+func (f *FileRevocationStore) records() []revocationRecord {
+	m := f.MemoryRevocationStore
+	m.mux.RLock()
+	defer m.mux.RUnlock()
+
+	now := time.Now()
+	ret := make([]revocationRecord, 0, len(m.ids)+len(m.subjects)+len(m.cutoffs))
+	for id, expires := range m.ids {
+		if expires.IsZero() {
+			ret = append(ret, revocationRecord{Id: id})
+		} else if now.Before(expires) {
+			ret = append(ret, revocationRecord{Id: id, ExpiresAt: NewNumericDate(expires)})
+		}
+	}
+	for subject := range m.subjects {
+		ret = append(ret, revocationRecord{Subject: subject})
+	}
+	for subject, cutoff := range m.cutoffs {
+		ret = append(ret, revocationRecord{Subject: subject, IssuedBefore: NewNumericDate(cutoff)})
+	}
+	return ret
+}
@@ -0,0 +1,148 @@
+package jwt
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func testRevocationStore(t *testing.T, store RevocationStore) {
+	now := time.Now()
+
+	assert.Nil(t, store.RevokeID("revoked", now.Add(time.Hour)))
+	assert.Nil(t, store.RevokeID("forever", time.Time{}))
+	assert.Nil(t, store.RevokeID("expired", now.Add(-time.Hour)))
+	assert.Nil(t, store.RevokeSubject("mallory"))
+	assert.Nil(t, store.RevokeIssuedBefore("alice", now))
+	assert.NotNil(t, store.RevokeID("", now.Add(time.Hour)))
+	assert.NotNil(t, store.RevokeSubject(""))
+
+	for _, c := range []struct {
+		id, subject string
+		issuedAt    time.Time
+		revoked     bool
+	}{
+		{"revoked", "", time.Time{}, true},
+		{"forever", "", time.Time{}, true},
+		{"expired", "", time.Time{}, false},
+		{"other", "", time.Time{}, false},
+		{"", "mallory", now.Add(time.Hour), true},
+		{"", "alice", now.Add(-time.Minute), true},
+		{"", "alice", time.Time{}, true},
+		{"", "alice", now.Add(time.Minute), false},
+		{"", "bob", time.Time{}, false},
+		{"", "", time.Time{}, false},
+	} {
+		revoked, err := store.IsRevoked(c.id, c.subject, c.issuedAt)
+		assert.Nil(t, err)
+		assert.Equal(t, c.revoked, revoked, "id '%s' subject '%s'", c.id, c.subject)
+	}
+}
+
+func TestMemoryRevocationStore(t *testing.T) {
+	testRevocationStore(t, NewMemoryRevocationStore())
+}
+
+func TestMemoryRevocationStoreExpiry(t *testing.T) {
+	store := NewMemoryRevocationStore()
+	assert.Nil(t, store.RevokeID("short", time.Now().Add(50*time.Millisecond)))
+	revoked, _ := store.IsRevoked("short", "", time.Time{})
+	assert.True(t, revoked)
+
+	time.Sleep(100 * time.Millisecond)
+	revoked, _ = store.IsRevoked("short", "", time.Time{})
+	assert.False(t, revoked)
+}
+
+func TestFileRevocationStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "revocations")
+	store, err := NewFileRevocationStore(path)
+	assert.Nil(t, err)
+	testRevocationStore(t, store)
+	assert.Nil(t, store.RevokeIssuedBefore("alice", time.Now().Add(-time.Hour)))
+	assert.Nil(t, store.Close())
+	assert.NotNil(t, store.RevokeSubject("closed"))
+
+	// Reloading restores the revocations, the expired one was never persisted
+	data, err := os.ReadFile(path)
+	assert.Nil(t, err)
+	assert.Equal(t, 5, bytes.Count(data, []byte("\n")))
+
+	store, err = NewFileRevocationStore(path)
+	assert.Nil(t, err)
+	defer store.Close()
+	revoked, _ := store.IsRevoked("", "alice", time.Now().Add(-time.Minute))
+	assert.True(t, revoked, "the latest cutoff is kept")
+	revoked, _ = store.IsRevoked("forever", "", time.Time{})
+	assert.True(t, revoked)
+	revoked, _ = store.IsRevoked("", "mallory", time.Time{})
+	assert.True(t, revoked)
+
+	// Compaction drops the superseded cutoff
+	data, err = os.ReadFile(path)
+	assert.Nil(t, err)
+	assert.Equal(t, 4, bytes.Count(data, []byte("\n")))
+}
+
+func TestFileRevocationStoreCompactsExpired(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "revocations")
+	store, err := NewFileRevocationStore(path)
+	assert.Nil(t, err)
+	assert.Nil(t, store.RevokeID("short", time.Now().Add(50*time.Millisecond)))
+	assert.Nil(t, store.RevokeID("long", time.Now().Add(time.Hour)))
+	assert.Nil(t, store.Close())
+
+	time.Sleep(100 * time.Millisecond)
+	store, err = NewFileRevocationStore(path)
+	assert.Nil(t, err)
+	defer store.Close()
+	data, err := os.ReadFile(path)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, bytes.Count(data, []byte("\n")))
+	assert.Contains(t, string(data), `"long"`)
+}
+
+func TestFileRevocationStoreInvalid(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "revocations")
+	assert.Nil(t, os.WriteFile(path, []byte("{\"sub\":\"alice\"}\nnot json\n"), 0600))
+	_, err := NewFileRevocationStore(path)
+	assert.EqualError(t, err, "invalid revocation file, line 2: invalid character 'o' in literal null (expecting 'u')")
+}
+
+func TestCheckRevocation(t *testing.T) {
+	store := NewMemoryRevocationStore()
+	claims := testClaims()
+	token, err := Sign(claims, testSecret)
+	assert.Nil(t, err)
+
+	data, err := ParseAndValidate(token, &ExtendedClaims{}, testSecret, WithRevocation(store))
+	assert.Nil(t, err)
+	assert.Nil(t, data.Revoke(store))
+
+	_, err = ParseAndValidate(token, &ExtendedClaims{}, testSecret, WithRevocation(store))
+	assert.True(t, ErrRevoked.IsType(err))
+}
+
+func TestCheckRevocationFailsClosed(t *testing.T) {
+	store := NewMemoryRevocationStore()
+	assert.Nil(t, store.RevokeSubject("1"))
+	assert.Nil(t, store.RevokeIssuedBefore("alice", time.Now()))
+
+	header := `{"alg":"HS256","typ":"JWT"}`
+	for _, claims := range []string{
+		`{"sub":1}`,
+		`{"sub":"alice","iat":"yesterday"}`,
+		`{"sub":"alice","jti":{"id":"x"}}`,
+	} {
+		// The claims target ignores the invalid claims, the revocation check must not
+		_, err := ParseAndValidate(signRaw(t, header, claims), &userClaims{}, testSecret, WithRevocation(store))
+		assert.True(t, ErrRevoked.IsType(err), claims)
+	}
+
+	_, err := ParseAndValidate(signRaw(t, header, `{"sub":"alice","iat":4102444800}`), &userClaims{}, testSecret, WithRevocation(store))
+	assert.Nil(t, err)
+}
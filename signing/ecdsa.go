@@ -3,75 +3,217 @@ package signing
 import (
 	"crypto"
 	"crypto/ecdsa"
+	"crypto/elliptic"
 	"crypto/rand"
+	"encoding/asn1"
 	"errors"
+	"fmt"
+	"io"
 	"math/big"
+	"sync/atomic"
 
-	"go.riotgames.com/ssp/go-jarvis/net/tokens/encoding"
+	"github.com/jucardi/go-jwt/encoding"
 )
 
 type ecdsaSigner struct {
-	alg       string
-	hash      crypto.Hash
-	keySize   int
-	curveBits int
+	alg           string
+	hash          crypto.Hash
+	curve         elliptic.Curve
+	deterministic atomic.Bool
+}
+
+// ecdsaSignature is the ASN.1 structure of a DER encoded ECDSA signature
+type ecdsaSignature struct {
+	R, S *big.Int
 }
 
 func (x *ecdsaSigner) Algorithm() string {
 	return x.alg
 }
 
+// keySize returns the size in bytes of each of the r and s values in a JOSE signature
+func (x *ecdsaSigner) keySize() int {
+	return (x.curve.Params().BitSize + 7) / 8
+}
+
 func (x *ecdsaSigner) Sign(signingString string, privateKey interface{}) (string, error) {
-	key, ok := privateKey.(*ecdsa.PrivateKey)
-	if !ok || key.Curve.Params().BitSize != x.curveBits {
-		return "", errors.New("invalid key, expected *ecdsa.PrivateKey")
+	k, opts := unwrapKey(privateKey)
+	key, ok := k.(*ecdsa.PrivateKey)
+	if !ok {
+		return "", invalidKey(x.alg, "invalid key, expected *ecdsa.PrivateKey")
+	}
+	if err := checkECDSAKey(x.alg, x.curve, &key.PublicKey); err != nil {
+		return "", err
 	}
 
-	hasher := x.hash.New()
-	hasher.Write([]byte(signingString))
+	d := getDigest(x.hash)
+	defer putDigest(d)
+	sum := d.hashSum([]byte(signingString))
 
-	// Sign the string and return r, s
-	r, s, err := ecdsa.Sign(rand.Reader, key, hasher.Sum(nil))
+	// A nil random source produces a deterministic RFC 6979 signature
+	var random io.Reader = rand.Reader
+	if opts.deterministic || x.deterministic.Load() {
+		random = nil
+	}
+	der, err := key.Sign(random, sum, x.hash)
 	if err != nil {
 		return "", err
 	}
+	raw, err := x.fromDER(der)
+	if err != nil {
+		return "", err
+	}
+	return encoding.EncodeSegment(raw), nil
+}
 
-	keyBytes := x.curveBits / 8
-	if x.curveBits%8 > 0 {
-		keyBytes += 1
+func (x *ecdsaSigner) Verify(signed, signature []byte, publicKey interface{}) error {
+	k, _ := unwrapKey(publicKey)
+	key, ok := k.(*ecdsa.PublicKey)
+	if !ok {
+		return invalidKey(x.alg, "invalid key, expected *ecdsa.PublicKey")
+	}
+	if err := checkECDSAKey(x.alg, x.curve, key); err != nil {
+		return err
 	}
 
-	// We serialize the outpus (r and s) into big-endian byte arrays and pad
-	// them with zeros on the left to make sure the sizes work out. Both arrays
-	// must be keyBytes long, and the output must be 2*keyBytes long.
-	rBytes := r.Bytes()
-	rBytesPadded := make([]byte, keyBytes)
-	copy(rBytesPadded[keyBytes-len(rBytes):], rBytes)
+	size := x.keySize()
+	if len(signature) != 2*size {
+		return fmt.Errorf("invalid signature length, %s requires %d bytes, got %d", x.alg, 2*size, len(signature))
+	}
 
-	sBytes := s.Bytes()
-	sBytesPadded := make([]byte, keyBytes)
-	copy(sBytesPadded[keyBytes-len(sBytes):], sBytes)
+	d := getDigest(x.hash)
+	defer putDigest(d)
 
-	out := append(rBytesPadded, sBytesPadded...)
+	// VerifyASN1 is used over Verify as the latter converts r and s to ASN.1 internally anyway. The
+	// grown buffer is kept in the digest, so it is reused once returned to the pool.
+	d.buf = appendDERSignature(d.buf[:0], signature[:size], signature[size:])
+	if !ecdsa.VerifyASN1(key, d.hashSum(signed), d.buf) {
+		return errors.New("token verification failed")
+	}
+	return nil
+}
 
-	return encoding.EncodeSegment(out), nil
+// serialize serializes r and s into big-endian byte arrays padded with zeros on the left so both
+// are keySize long, and returns their concatenation (r||s) as required by RFC 7518
+func (x *ecdsaSigner) serialize(r, s *big.Int) []byte {
+	size := x.keySize()
+	out := make([]byte, 2*size)
+	r.FillBytes(out[:size])
+	s.FillBytes(out[size:])
+	return out
 }
 
-func (x *ecdsaSigner) Verify(signed, signature []byte, publicKey interface{}) error {
-	key, ok := publicKey.(*ecdsa.PublicKey)
-	if !ok || len(signature) != 2*x.keySize {
-		return errors.New("invalid key, expected *ecdsa.PublicKey")
+// appendDERSignature appends the ASN.1 DER encoding of the signature with the provided big-endian
+// r and s values to dst, without the intermediate big.Int values of asn1.Marshal
+func appendDERSignature(dst, r, s []byte) []byte {
+	r, s = trimLeadingZeros(r), trimLeadingZeros(s)
+	intLen := func(v []byte) int {
+		if len(v) == 0 || v[0]&0x80 != 0 {
+			return len(v) + 1
+		}
+		return len(v)
+	}
+	appendLen := func(dst []byte, n int) []byte {
+		if n < 0x80 {
+			return append(dst, byte(n))
+		}
+		return append(dst, 0x81, byte(n))
+	}
+	appendInt := func(dst, v []byte) []byte {
+		dst = append(dst, 0x02)
+		dst = appendLen(dst, intLen(v))
+		if len(v) == 0 || v[0]&0x80 != 0 {
+			dst = append(dst, 0)
+		}
+		return append(dst, v...)
+	}
+	rLen, sLen := intLen(r), intLen(s)
+	seqLen := 2 + rLen + 2 + sLen
+	if rLen >= 0x80 {
+		seqLen++
 	}
+	if sLen >= 0x80 {
+		seqLen++
+	}
+	dst = append(dst, 0x30)
+	dst = appendLen(dst, seqLen)
+	dst = appendInt(dst, r)
+	return appendInt(dst, s)
+}
 
-	hasher := x.hash.New()
-	hasher.Write(signed)
+func trimLeadingZeros(v []byte) []byte {
+	for len(v) > 0 && v[0] == 0 {
+		v = v[1:]
+	}
+	return v
+}
 
-	r := big.NewInt(0).SetBytes(signature[:x.keySize])
-	s := big.NewInt(0).SetBytes(signature[x.keySize:])
+// ECDSASignatureToDER converts a JOSE ECDSA signature (r||s) into the ASN.1 DER encoding used by
+// most crypto libraries and HSMs
+//
+//   {alg}       - The ECDSA algorithm the signature was produced with (ES256, ES384, ES512)
+//   {signature} - The raw r||s signature, as found in the third segment of the token once decoded
+//
+func ECDSASignatureToDER(alg Algorithm, signature []byte) ([]byte, error) {
+	x, err := ecdsaSignerFor(alg)
+	if err != nil {
+		return nil, err
+	}
+	size := x.keySize()
+	if len(signature) != 2*size {
+		return nil, fmt.Errorf("invalid signature length, %s requires %d bytes, got %d", alg, 2*size, len(signature))
+	}
+	return appendDERSignature(nil, signature[:size], signature[size:]), nil
+}
 
-	// Verify the signature
-	if !ecdsa.Verify(key, hasher.Sum(nil), r, s) {
-		return errors.New("token verification failed")
+// ECDSASignatureFromDER converts an ASN.1 DER encoded ECDSA signature, such as the ones produced by
+// HSMs or `crypto.Signer` implementations, into the JOSE r||s format required by the algorithm
+//
+//   {alg}       - The ECDSA algorithm the signature was produced with (ES256, ES384, ES512)
+//   {signature} - The DER encoded signature
+//
+func ECDSASignatureFromDER(alg Algorithm, signature []byte) ([]byte, error) {
+	x, err := ecdsaSignerFor(alg)
+	if err != nil {
+		return nil, err
 	}
+	return x.fromDER(signature)
+}
+
+// fromDER converts an ASN.1 DER encoded signature into the JOSE r||s format
+func (x *ecdsaSigner) fromDER(signature []byte) ([]byte, error) {
+	var sig ecdsaSignature
+	if rest, err := asn1.Unmarshal(signature, &sig); err != nil {
+		return nil, fmt.Errorf("failed to parse DER signature, %s", err.Error())
+	} else if len(rest) > 0 {
+		return nil, errors.New("failed to parse DER signature, trailing data")
+	}
+	n := x.curve.Params().N
+	if sig.R.Sign() <= 0 || sig.S.Sign() <= 0 || sig.R.Cmp(n) >= 0 || sig.S.Cmp(n) >= 0 {
+		return nil, fmt.Errorf("invalid DER signature, r and s must be in the range of curve %s", x.curve.Params().Name)
+	}
+	return x.serialize(sig.R, sig.S), nil
+}
+
+// SetDeterministic enables or disables RFC 6979 deterministic nonces for every signature produced
+// by the provided ECDSA algorithm. To use deterministic nonces only for a specific call, wrap the
+// key with `DeterministicKey` instead.
+//
+//   {alg}     - The ECDSA algorithm (ES256, ES384, ES512)
+//   {enabled} - Whether deterministic nonces should be used
+//
+func SetDeterministic(alg Algorithm, enabled bool) error {
+	x, err := ecdsaSignerFor(alg)
+	if err != nil {
+		return err
+	}
+	x.deterministic.Store(enabled)
 	return nil
 }
+
+func ecdsaSignerFor(alg Algorithm) (*ecdsaSigner, error) {
+	if x, ok := alg.Signer().(*ecdsaSigner); ok {
+		return x, nil
+	}
+	return nil, fmt.Errorf("algorithm '%s' is not an ECDSA algorithm", alg)
+}
@@ -0,0 +1,130 @@
+package signing
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/hex"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/jucardi/go-jwt/encoding"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDefaultFromKeyCurves(t *testing.T) {
+	for curve, alg := range map[elliptic.Curve]Algorithm{
+		elliptic.P256(): AlgorithmES256,
+		elliptic.P384(): AlgorithmES384,
+		elliptic.P521(): AlgorithmES512,
+	} {
+		key, err := ecdsa.GenerateKey(curve, rand.Reader)
+		assert.Nil(t, err)
+		assert.Equal(t, alg, DefaultFromKey(key), curve.Params().Name)
+		assert.Equal(t, alg, DefaultFromKey(&key.PublicKey), curve.Params().Name)
+		assert.Equal(t, alg, DefaultFromKey(WeakKey(key)), curve.Params().Name)
+
+		signer := DefaultFromKey(key).Signer()
+		signature, err := signer.Sign("payload", key)
+		assert.Nil(t, err, curve.Params().Name)
+		raw, err := encoding.DecodeSegment(signature)
+		assert.Nil(t, err)
+		assert.Nil(t, signer.Verify([]byte("payload"), raw, &key.PublicKey), curve.Params().Name)
+	}
+}
+
+func TestECDSACurveBinding(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	assert.Nil(t, err)
+	_, err = AlgorithmES256.Signer().Sign("payload", key)
+	assert.NotNil(t, err)
+}
+
+// rfc6979Vectors are the test vectors of RFC 6979 appendix A.2.5 (P-256, SHA-256), A.2.6 (P-384,
+// SHA-384) and A.2.7 (P-521, SHA-512)
+var rfc6979Vectors = []struct {
+	alg     Algorithm
+	curve   elliptic.Curve
+	d       string
+	message string
+	r, s    string
+}{
+	{
+		alg: AlgorithmES256, curve: elliptic.P256(),
+		d:       "C9AFA9D845BA75166B5C215767B1D6934E50C3DB36E89B127B8A622B120F6721",
+		message: "sample",
+		r:       "EFD48B2AACB6A8FD1140DD9CD45E81D69D2C877B56AAF991C34D0EA84EAF3716",
+		s:       "F7CB1C942D657C41D436C7A1B6E29F65F3E900DBB9AFF4064DC4AB2F843ACDA8",
+	},
+	{
+		alg: AlgorithmES256, curve: elliptic.P256(),
+		d:       "C9AFA9D845BA75166B5C215767B1D6934E50C3DB36E89B127B8A622B120F6721",
+		message: "test",
+		r:       "F1ABB023518351CD71D881567B1EA663ED3EFCF6C5132B354F28D3B0B7D38367",
+		s:       "019F4113742A2B14BD25926B49C649155F267E60D3814B4C0CC84250E46F0083",
+	},
+	{
+		alg: AlgorithmES384, curve: elliptic.P384(),
+		d:       "6B9D3DAD2E1B8C1C05B19875B6659F4DE23C3B667BF297BA9AA47740787137D896D5724E4C70A825F872C9EA60D2EDF5",
+		message: "sample",
+		r:       "94EDBB92A5ECB8AAD4736E56C691916B3F88140666CE9FA73D64C4EA95AD133C81A648152E44ACF96E36DD1E80FABE46",
+		s:       "99EF4AEB15F178CEA1FE40DB2603138F130E740A19624526203B6351D0A3A94FA329C145786E679E7B82C71A38628AC8",
+	},
+	{
+		alg: AlgorithmES384, curve: elliptic.P384(),
+		d:       "6B9D3DAD2E1B8C1C05B19875B6659F4DE23C3B667BF297BA9AA47740787137D896D5724E4C70A825F872C9EA60D2EDF5",
+		message: "test",
+		r:       "8203B63D3C853E8D77227FB377BCF7B7B772E97892A80F36AB775D509D7A5FEB0542A7F0812998DA8F1DD3CA3CF023DB",
+		s:       "DDD0760448D42D8A43AF45AF836FCE4DE8BE06B485E9B61B827C2F13173923E06A739F040649A667BF3B828246BAA5A5",
+	},
+	{
+		alg: AlgorithmES512, curve: elliptic.P521(),
+		d:       "0FAD06DAA62BA3B25D2FB40133DA757205DE67F5BB0018FEE8C86E1B68C7E75CAA896EB32F1F47C70855836A6D16FCC1466F6D8FBEC67DB89EC0C08B0E996B83538",
+		message: "sample",
+		r:       "00C328FAFCBD79DD77850370C46325D987CB525569FB63C5D3BC53950E6D4C5F174E25A1EE9017B5D450606ADD152B534931D7D4E8455CC91F9B15BF05EC36E377FA",
+		s:       "00617CCE7CF5064806C467F678D3B4080D6F1CC50AF26CA209417308281B68AF282623EAA63E5B5C0723D8B8C37FF0777B1A20F8CCB1DCCC43997F1EE0E44DA4A67A",
+	},
+	{
+		alg: AlgorithmES512, curve: elliptic.P521(),
+		d:       "0FAD06DAA62BA3B25D2FB40133DA757205DE67F5BB0018FEE8C86E1B68C7E75CAA896EB32F1F47C70855836A6D16FCC1466F6D8FBEC67DB89EC0C08B0E996B83538",
+		message: "test",
+		r:       "013E99020ABF5CEE7525D16B69B229652AB6BDF2AFFCAEF38773B4B7D08725F10CDB93482FDCC54EDCEE91ECA4166B2A7C6265EF0CE2BD7051B7CEF945BABD47EE6D",
+		s:       "01FBD0013C674AA79CB39849527916CE301C66EA7CE8B80682786AD60F98F7E78A19CA69EFF5C57400E3B3A0AD66CE0978214D13BAF4E9AC60752F7B155E2DE4DCE3",
+	},
+}
+
+func TestDeterministicSignatures(t *testing.T) {
+	for _, v := range rfc6979Vectors {
+		name := v.alg.String() + " " + v.message
+		d, _ := new(big.Int).SetString(v.d, 16)
+		key := &ecdsa.PrivateKey{D: d}
+		key.Curve = v.curve
+		key.X, key.Y = v.curve.ScalarBaseMult(d.Bytes())
+
+		signature, err := v.alg.Signer().Sign(v.message, DeterministicKey(key))
+		assert.Nil(t, err, name)
+		raw, err := encoding.DecodeSegment(signature)
+		assert.Nil(t, err, name)
+		assert.Equal(t, v.r+v.s, strings.ToUpper(hex.EncodeToString(raw)), name)
+		assert.Nil(t, v.alg.Signer().Verify([]byte(v.message), raw, &key.PublicKey), name)
+	}
+}
+
+func TestSetDeterministic(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.Nil(t, err)
+	signer := AlgorithmES256.Signer()
+
+	first, _ := signer.Sign("payload", key)
+	second, _ := signer.Sign("payload", key)
+	assert.NotEqual(t, first, second)
+
+	assert.Nil(t, SetDeterministic(AlgorithmES256, true))
+	defer SetDeterministic(AlgorithmES256, false)
+	first, _ = signer.Sign("payload", key)
+	second, _ = signer.Sign("payload", key)
+	assert.Equal(t, first, second)
+
+	assert.NotNil(t, SetDeterministic(AlgorithmHS256, true))
+}
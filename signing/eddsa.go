@@ -0,0 +1,37 @@
+package signing
+
+import (
+	"crypto/ed25519"
+	"errors"
+
+	"github.com/jucardi/go-jwt/encoding"
+)
+
+type eddsaSigner struct {
+	alg string
+}
+
+func (x *eddsaSigner) Algorithm() string {
+	return x.alg
+}
+
+func (x *eddsaSigner) Sign(signingString string, privateKey interface{}) (string, error) {
+	k, _ := unwrapKey(privateKey)
+	key, ok := k.(ed25519.PrivateKey)
+	if !ok || len(key) != ed25519.PrivateKeySize {
+		return "", invalidKey(x.alg, "invalid key, expected ed25519.PrivateKey")
+	}
+	return encoding.EncodeSegment(ed25519.Sign(key, []byte(signingString))), nil
+}
+
+func (x *eddsaSigner) Verify(signed, signature []byte, publicKey interface{}) error {
+	k, _ := unwrapKey(publicKey)
+	key, ok := k.(ed25519.PublicKey)
+	if !ok || len(key) != ed25519.PublicKeySize {
+		return invalidKey(x.alg, "invalid key, expected ed25519.PublicKey")
+	}
+	if !ed25519.Verify(key, signed, signature) {
+		return errors.New("token verification failed")
+	}
+	return nil
+}
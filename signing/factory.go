@@ -3,7 +3,11 @@ package signing
 import (
 	"crypto"
 	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
 	"crypto/rsa"
+	_ "crypto/sha256"
+	_ "crypto/sha512"
 )
 
 const (
@@ -16,6 +20,8 @@ const (
 	AlgorithmES256 Algorithm = "ES256"
 	AlgorithmES384 Algorithm = "ES384"
 	AlgorithmES512 Algorithm = "ES512"
+	AlgorithmEdDSA Algorithm = "EdDSA"
+	AlgorithmNone  Algorithm = "none"
 )
 
 // Algorithm indicates the signing algorithm to be used
@@ -43,19 +49,42 @@ var signers = map[Algorithm]ISigner{
 	AlgorithmRS384: &rsaSigner{alg: "RS384", hash: crypto.SHA384},
 	AlgorithmRS512: &rsaSigner{alg: "RS512", hash: crypto.SHA512},
 
-	AlgorithmES256: &ecdsaSigner{alg: "ES256", hash: crypto.SHA256},
-	AlgorithmES384: &ecdsaSigner{alg: "ES384", hash: crypto.SHA384},
-	AlgorithmES512: &ecdsaSigner{alg: "ES512", hash: crypto.SHA512},
+	AlgorithmES256: &ecdsaSigner{alg: "ES256", hash: crypto.SHA256, curve: elliptic.P256()},
+	AlgorithmES384: &ecdsaSigner{alg: "ES384", hash: crypto.SHA384, curve: elliptic.P384()},
+	AlgorithmES512: &ecdsaSigner{alg: "ES512", hash: crypto.SHA512, curve: elliptic.P521()},
+
+	AlgorithmEdDSA: &eddsaSigner{alg: "EdDSA"},
+
+	AlgorithmNone: &noneSigner{},
 }
 
 func DefaultFromKey(key interface{}) Algorithm {
-	switch key.(type) {
-	case *ecdsa.PrivateKey, *ecdsa.PublicKey:
-		return AlgorithmES256
+	key, _ = unwrapKey(key)
+	switch k := key.(type) {
+	case *ecdsa.PrivateKey:
+		return ecdsaAlgorithm(k.Curve)
+	case *ecdsa.PublicKey:
+		return ecdsaAlgorithm(k.Curve)
 	case *rsa.PublicKey, *rsa.PrivateKey:
 		return AlgorithmRS256
+	case ed25519.PrivateKey, ed25519.PublicKey:
+		return AlgorithmEdDSA
 	case []byte:
 		return AlgorithmHS256
+	case unsafeNoneKey:
+		return AlgorithmNone
 	}
 	return ""
 }
+
+// ecdsaAlgorithm returns the ECDSA algorithm bound to the provided curve, ES256 if the curve is
+// not supported so the signer reports the curve mismatch
+func ecdsaAlgorithm(curve elliptic.Curve) Algorithm {
+	switch curve {
+	case elliptic.P384():
+		return AlgorithmES384
+	case elliptic.P521():
+		return AlgorithmES512
+	}
+	return AlgorithmES256
+}
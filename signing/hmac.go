@@ -5,7 +5,7 @@ import (
 	"crypto/hmac"
 	"errors"
 
-	"go.riotgames.com/ssp/go-jarvis/net/tokens/encoding"
+	"github.com/jucardi/go-jwt/encoding"
 )
 
 type hmacSigner struct {
@@ -18,27 +18,33 @@ func (r *hmacSigner) Algorithm() string {
 }
 
 func (r *hmacSigner) Sign(signingString string, privateKey interface{}) (string, error) {
-	key, ok := privateKey.([]byte)
+	k, opts := unwrapKey(privateKey)
+	key, ok := k.([]byte)
 	if !ok {
-		return "", errors.New("invalid key, expected []byte")
+		return "", invalidKey(r.alg, "invalid key, expected []byte")
+	}
+	if err := checkHMACKey(r.alg, r.hash, key, !opts.weak); err != nil {
+		return "", err
 	}
 
-	hasher := hmac.New(r.hash.New, key)
-	hasher.Write([]byte(signingString))
-
-	return encoding.EncodeSegment(hasher.Sum(nil)), nil
+	d := getHMAC(r.hash, key)
+	defer putHMAC(d)
+	return encoding.EncodeSegment(d.hmacSum([]byte(signingString))), nil
 }
 
 func (r *hmacSigner) Verify(signed, signature []byte, publicKey interface{}) error {
-	key, ok := publicKey.([]byte)
+	k, opts := unwrapKey(publicKey)
+	key, ok := k.([]byte)
 	if !ok {
-		return errors.New("invalid key, expected []byte")
+		return invalidKey(r.alg, "invalid key, expected []byte")
+	}
+	if err := checkHMACKey(r.alg, r.hash, key, !opts.weak); err != nil {
+		return err
 	}
 
-	hasher := hmac.New(r.hash.New, key)
-	hasher.Write(signed)
-
-	if !hmac.Equal(signature, hasher.Sum(nil)) {
+	d := getHMAC(r.hash, key)
+	defer putHMAC(d)
+	if !hmac.Equal(signature, d.hmacSum(signed)) {
 		return errors.New("invalid signature")
 	}
 	return nil
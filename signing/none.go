@@ -0,0 +1,33 @@
+package signing
+
+import "errors"
+
+// UnsafeAllowNone is the sentinel key that must be provided in place of a private or public key
+// to sign or verify unsecured tokens ("alg": "none"). Unsecured tokens carry no signature and
+// anyone can forge them, they are meant for test fixtures only.
+const UnsafeAllowNone unsafeNoneKey = "I understand unsecured tokens can be forged by anyone"
+
+type unsafeNoneKey string
+
+type noneSigner struct{}
+
+func (n *noneSigner) Algorithm() string {
+	return AlgorithmNone.String()
+}
+
+func (n *noneSigner) Sign(signingString string, privateKey interface{}) (string, error) {
+	if k, _ := unwrapKey(privateKey); k != UnsafeAllowNone {
+		return "", invalidKey(n.Algorithm(), "invalid key, unsecured tokens can only be signed with signing.UnsafeAllowNone")
+	}
+	return "", nil
+}
+
+func (n *noneSigner) Verify(signed, signature []byte, publicKey interface{}) error {
+	if k, _ := unwrapKey(publicKey); k != UnsafeAllowNone {
+		return invalidKey(n.Algorithm(), "invalid key, unsecured tokens can only be verified with signing.UnsafeAllowNone")
+	}
+	if len(signature) != 0 {
+		return errors.New("unsecured tokens must have an empty signature")
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+package signing
+
+// WeakKey wraps a key to explicitly opt out of the minimum strength checks of the current
+// `KeyPolicy`. Meant for legacy keys that cannot be rotated yet, the key type and the curve bound
+// to ECDSA algorithms are still verified.
+//
+//   {key} - The private or public key that does not meet the key policy
+//
+func WeakKey(key interface{}) interface{} {
+	w := wrapKey(key)
+	w.weak = true
+	return w
+}
+
+// DeterministicKey wraps an ECDSA private key so the signature is produced with a deterministic
+// nonce as defined in RFC 6979 instead of a random one. Signing the same input with the same key
+// always produces the same signature. It has no effect on other key types.
+//
+//   {key} - The *ecdsa.PrivateKey to sign with
+//
+func DeterministicKey(key interface{}) interface{} {
+	w := wrapKey(key)
+	w.deterministic = true
+	return w
+}
+
+// keyOptions holds the options a key was wrapped with
+type keyOptions struct {
+	key           interface{}
+	weak          bool
+	deterministic bool
+}
+
+func wrapKey(key interface{}) *keyOptions {
+	if w, ok := key.(*keyOptions); ok {
+		ret := *w
+		return &ret
+	}
+	return &keyOptions{key: key}
+}
+
+// unwrapKey returns the actual key and the options it was wrapped with, if any
+func unwrapKey(key interface{}) (interface{}, keyOptions) {
+	if w, ok := key.(*keyOptions); ok {
+		return w.key, *w
+	}
+	return key, keyOptions{key: key}
+}
+
+// UnwrapKey returns the key wrapped by `WeakKey` or `DeterministicKey`. Keys that were not wrapped
+// are returned as is.
+//
+//   {key} - The key, wrapped or not
+//
+func UnwrapKey(key interface{}) interface{} {
+	ret, _ := unwrapKey(key)
+	return ret
+}
@@ -0,0 +1,103 @@
+package signing
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"errors"
+	"fmt"
+	"sync/atomic"
+)
+
+// ErrInvalidKey is the error all key validation errors match with `errors.Is`
+var ErrInvalidKey = errors.New("invalid key")
+
+// InvalidKeyError indicates the provided key is of the wrong type for the algorithm or does not
+// meet the minimum strength required by the current `KeyPolicy`
+type InvalidKeyError struct {
+	Algorithm string
+	Message   string
+}
+
+// Error returns the error message
+func (e *InvalidKeyError) Error() string {
+	return e.Message
+}
+
+// Is allows `errors.Is(err, ErrInvalidKey)` to match any *InvalidKeyError
+func (e *InvalidKeyError) Is(target error) bool {
+	return target == ErrInvalidKey
+}
+
+func invalidKey(alg, format string, args ...interface{}) error {
+	return &InvalidKeyError{Algorithm: alg, Message: fmt.Sprintf(format, args...)}
+}
+
+// KeyPolicy defines the minimum key strength enforced by the signers on both sign and verify
+type KeyPolicy struct {
+	// MinRSABits is the minimum RSA modulus size in bits
+	MinRSABits int
+	// MinHMACKeySize is the minimum HMAC secret length in bytes. If zero, the secret must be at
+	// least as long as the output of the hash used by the algorithm (32, 48 and 64 bytes for
+	// HS256, HS384 and HS512 respectively)
+	MinHMACKeySize int
+}
+
+// DefaultKeyPolicy is the strict policy in place unless `SetKeyPolicy` is invoked
+var DefaultKeyPolicy = KeyPolicy{
+	MinRSABits: 2048,
+}
+
+var policy atomic.Value
+
+func init() {
+	policy.Store(DefaultKeyPolicy)
+}
+
+// SetKeyPolicy replaces the key strength policy enforced by all the signers
+//
+//   {p} - The new key policy
+//
+func SetKeyPolicy(p KeyPolicy) {
+	policy.Store(p)
+}
+
+// GetKeyPolicy returns the key strength policy currently enforced by the signers
+func GetKeyPolicy() KeyPolicy {
+	return policy.Load().(KeyPolicy)
+}
+
+func checkHMACKey(alg string, hash crypto.Hash, key []byte, enforce bool) error {
+	if !enforce {
+		return nil
+	}
+	min := GetKeyPolicy().MinHMACKeySize
+	if min <= 0 {
+		min = hash.Size()
+	}
+	if len(key) < min {
+		return invalidKey(alg, "invalid key, %s requires a secret of at least %d bytes, got %d", alg, min, len(key))
+	}
+	return nil
+}
+
+func checkRSAKey(alg string, key *rsa.PublicKey, enforce bool) error {
+	if key.N == nil {
+		return invalidKey(alg, "invalid key, RSA modulus is nil")
+	}
+	if !enforce {
+		return nil
+	}
+	if min := GetKeyPolicy().MinRSABits; key.N.BitLen() < min {
+		return invalidKey(alg, "invalid key, %s requires an RSA key of at least %d bits, got %d", alg, min, key.N.BitLen())
+	}
+	return nil
+}
+
+func checkECDSAKey(alg string, curve elliptic.Curve, key *ecdsa.PublicKey) error {
+	if key.Curve == nil || key.Curve.Params().Name != curve.Params().Name {
+		return invalidKey(alg, "invalid key, %s requires an ECDSA key on curve %s", alg, curve.Params().Name)
+	}
+	return nil
+}
@@ -0,0 +1,72 @@
+package signing
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"errors"
+	"testing"
+
+	"github.com/jucardi/go-jwt/encoding"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHMACKeyPolicy(t *testing.T) {
+	for alg, size := range map[Algorithm]int{AlgorithmHS256: 32, AlgorithmHS384: 48, AlgorithmHS512: 64} {
+		signer := alg.Signer()
+		short, valid := make([]byte, size-1), make([]byte, size)
+
+		_, err := signer.Sign("payload", short)
+		assert.True(t, errors.Is(err, ErrInvalidKey), alg.String())
+		assert.Contains(t, err.Error(), "requires a secret of at least")
+
+		signature, err := signer.Sign("payload", valid)
+		assert.Nil(t, err, alg.String())
+		raw, err := encoding.DecodeSegment(signature)
+		assert.Nil(t, err)
+		assert.Nil(t, signer.Verify([]byte("payload"), raw, valid), alg.String())
+		assert.True(t, errors.Is(signer.Verify([]byte("payload"), raw, short), ErrInvalidKey), alg.String())
+
+		// The policy can be bypassed explicitly for a single key
+		signature, err = signer.Sign("payload", WeakKey(short))
+		assert.Nil(t, err, alg.String())
+		raw, _ = encoding.DecodeSegment(signature)
+		assert.Nil(t, signer.Verify([]byte("payload"), raw, WeakKey(short)), alg.String())
+	}
+}
+
+func TestRSAKeyPolicy(t *testing.T) {
+	weak, err := rsa.GenerateKey(rand.Reader, 1024)
+	assert.Nil(t, err)
+	signer := AlgorithmRS256.Signer()
+
+	_, err = signer.Sign("payload", weak)
+	assert.True(t, errors.Is(err, ErrInvalidKey))
+	assert.Contains(t, err.Error(), "at least 2048 bits")
+
+	signature, err := signer.Sign("payload", WeakKey(weak))
+	assert.Nil(t, err)
+	raw, err := encoding.DecodeSegment(signature)
+	assert.Nil(t, err)
+	assert.True(t, errors.Is(signer.Verify([]byte("payload"), raw, &weak.PublicKey), ErrInvalidKey))
+	assert.Nil(t, signer.Verify([]byte("payload"), raw, WeakKey(&weak.PublicKey)))
+
+	// The wrong key type is an invalid key as well
+	_, err = signer.Sign("payload", []byte("secret"))
+	assert.True(t, errors.Is(err, ErrInvalidKey))
+	assert.True(t, errors.Is(signer.Verify([]byte("payload"), raw, []byte("secret")), ErrInvalidKey))
+}
+
+func TestSetKeyPolicy(t *testing.T) {
+	defer SetKeyPolicy(GetKeyPolicy())
+	SetKeyPolicy(KeyPolicy{MinRSABits: 1024, MinHMACKeySize: 16})
+
+	weak, err := rsa.GenerateKey(rand.Reader, 1024)
+	assert.Nil(t, err)
+	_, err = AlgorithmRS256.Signer().Sign("payload", weak)
+	assert.Nil(t, err)
+
+	_, err = AlgorithmHS512.Signer().Sign("payload", make([]byte, 16))
+	assert.Nil(t, err)
+	_, err = AlgorithmHS512.Signer().Sign("payload", make([]byte, 15))
+	assert.True(t, errors.Is(err, ErrInvalidKey))
+}
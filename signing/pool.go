@@ -0,0 +1,94 @@
+package signing
+
+import (
+	"crypto"
+	"crypto/hmac"
+	"hash"
+	"sync"
+)
+
+// maxDigestSize is the size of the largest digest produced by the supported hashes (SHA-512)
+const maxDigestSize = 64
+
+// digest is a reusable hasher with a buffer to hold its sum, so hashing does not allocate
+type digest struct {
+	hash crypto.Hash
+	h    hash.Hash
+	sum  [maxDigestSize]byte
+	buf  []byte // scratch space for DER encoded signatures
+}
+
+var digestPools sync.Map // crypto.Hash -> *sync.Pool
+
+// getDigest obtains a reset hasher for the provided hash from the pool
+func getDigest(h crypto.Hash) *digest {
+	pool, ok := digestPools.Load(h)
+	if !ok {
+		pool, _ = digestPools.LoadOrStore(h, &sync.Pool{
+			New: func() interface{} {
+				return &digest{hash: h, h: h.New()}
+			},
+		})
+	}
+	return pool.(*sync.Pool).Get().(*digest)
+}
+
+// putDigest resets the hasher and returns it to the pool
+func putDigest(d *digest) {
+	d.h.Reset()
+	if pool, ok := digestPools.Load(d.hash); ok {
+		pool.(*sync.Pool).Put(d)
+	}
+}
+
+// hashSum hashes the provided data and returns the sum. The returned slice is only valid until
+// the digest is returned to the pool.
+func (d *digest) hashSum(data []byte) []byte {
+	d.h.Write(data)
+	return d.h.Sum(d.sum[:0])
+}
+
+// hmacDigest is a reusable HMAC hasher keyed with the last key it was used with, so verifying many
+// tokens with the same key does not allocate
+type hmacDigest struct {
+	hash crypto.Hash
+	key  []byte
+	mac  hash.Hash
+	sum  [maxDigestSize]byte
+}
+
+var hmacPools sync.Map // crypto.Hash -> *sync.Pool
+
+// getHMAC obtains a reset HMAC hasher for the provided hash and key from the pool. The hasher is
+// only created again if it was keyed with a different key.
+func getHMAC(h crypto.Hash, key []byte) *hmacDigest {
+	pool, ok := hmacPools.Load(h)
+	if !ok {
+		pool, _ = hmacPools.LoadOrStore(h, &sync.Pool{
+			New: func() interface{} {
+				return &hmacDigest{hash: h}
+			},
+		})
+	}
+	d := pool.(*sync.Pool).Get().(*hmacDigest)
+	if d.mac == nil || !hmac.Equal(d.key, key) {
+		d.key = append(d.key[:0], key...)
+		d.mac = hmac.New(h.New, d.key)
+	}
+	return d
+}
+
+// putHMAC resets the HMAC hasher and returns it to the pool
+func putHMAC(d *hmacDigest) {
+	d.mac.Reset()
+	if pool, ok := hmacPools.Load(d.hash); ok {
+		pool.(*sync.Pool).Put(d)
+	}
+}
+
+// hmacSum computes the HMAC of the provided data. The returned slice is only valid until the hasher
+// is returned to the pool.
+func (d *hmacDigest) hmacSum(data []byte) []byte {
+	d.mac.Write(data)
+	return d.mac.Sum(d.sum[:0])
+}
@@ -0,0 +1,132 @@
+package signing
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/asn1"
+	"math/big"
+	"testing"
+
+	"github.com/jucardi/go-jwt/encoding"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHMACPool(t *testing.T) {
+	data := []byte("header.claims")
+	for _, hash := range []crypto.Hash{crypto.SHA256, crypto.SHA384, crypto.SHA512} {
+		blockSize := hash.New().BlockSize()
+		for _, size := range []int{0, 1, hash.Size(), blockSize - 1, blockSize, blockSize + 1, 3 * blockSize} {
+			key := make([]byte, size)
+			rand.Read(key)
+
+			mac := hmac.New(hash.New, key)
+			mac.Write(data)
+			expected := mac.Sum(nil)
+
+			// Run twice so the second run uses a hasher returned to the pool, keyed with this key or
+			// with the key of the previous size
+			for i := 0; i < 2; i++ {
+				d := getHMAC(hash, key)
+				assert.Equal(t, expected, d.hmacSum(data), "%s key size %d", hash, size)
+				putHMAC(d)
+			}
+		}
+	}
+}
+
+func TestVerifyAllocs(t *testing.T) {
+	if raceEnabled {
+		t.Skip("sync.Pool drops items randomly with the race detector")
+	}
+	signed := []byte("header.claims")
+
+	key := make([]byte, 32)
+	rand.Read(key)
+	hs256 := AlgorithmHS256.Signer()
+	signature, err := hs256.Sign(string(signed), key)
+	assert.Nil(t, err)
+	raw, _ := encoding.DecodeSegment(signature)
+	// Boxed once, as keys are passed around as interface{}
+	var publicKey interface{} = key
+	assert.Equal(t, 0.0, testing.AllocsPerRun(100, func() {
+		if err := hs256.Verify(signed, raw, publicKey); err != nil {
+			t.Fatal(err)
+		}
+	}))
+
+	// The pooled hashing and DER encoding add no allocations to those of crypto/ecdsa
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.Nil(t, err)
+	es256 := AlgorithmES256.Signer()
+	signature, err = es256.Sign(string(signed), ecKey)
+	assert.Nil(t, err)
+	raw, _ = encoding.DecodeSegment(signature)
+	der, err := ECDSASignatureToDER(AlgorithmES256, raw)
+	assert.Nil(t, err)
+	sum := sha256.Sum256(signed)
+	expected := testing.AllocsPerRun(100, func() {
+		ecdsa.VerifyASN1(&ecKey.PublicKey, sum[:], der)
+	})
+	assert.Equal(t, expected, testing.AllocsPerRun(100, func() {
+		if err := es256.Verify(signed, raw, &ecKey.PublicKey); err != nil {
+			t.Fatal(err)
+		}
+	}))
+}
+
+func TestAppendDERSignature(t *testing.T) {
+	values := [][]byte{
+		{0x01},
+		{0x7f},
+		{0x80},
+		{0x00, 0x00, 0x80},
+		bytes.Repeat([]byte{0xff}, 32),
+		bytes.Repeat([]byte{0x7f}, 48),
+		bytes.Repeat([]byte{0xff}, 66),
+		append([]byte{0x01}, bytes.Repeat([]byte{0xff}, 65)...),
+	}
+	for i := 0; i < 20; i++ {
+		v := make([]byte, 1+i*4)
+		rand.Read(v)
+		values = append(values, v)
+	}
+
+	for _, r := range values {
+		for _, s := range values {
+			rv, sv := new(big.Int).SetBytes(r), new(big.Int).SetBytes(s)
+			if rv.Sign() == 0 || sv.Sign() == 0 {
+				continue
+			}
+			expected, err := asn1.Marshal(ecdsaSignature{R: rv, S: sv})
+			assert.Nil(t, err)
+			assert.Equal(t, expected, appendDERSignature(nil, r, s), "r=%x s=%x", r, s)
+		}
+	}
+}
+
+func TestECDSASignatureDERConversion(t *testing.T) {
+	for _, alg := range []Algorithm{AlgorithmES256, AlgorithmES384, AlgorithmES512} {
+		x, err := ecdsaSignerFor(alg)
+		assert.Nil(t, err)
+		raw := make([]byte, 2*x.keySize())
+		rand.Read(raw)
+		// Keep r and s below the curve order
+		raw[0], raw[x.keySize()] = 0, 0
+
+		der, err := ECDSASignatureToDER(alg, raw)
+		assert.Nil(t, err)
+		back, err := ECDSASignatureFromDER(alg, der)
+		assert.Nil(t, err)
+		assert.Equal(t, raw, back, alg.String())
+	}
+
+	_, err := ECDSASignatureToDER(AlgorithmES256, make([]byte, 63))
+	assert.NotNil(t, err)
+	_, err = ECDSASignatureFromDER(AlgorithmES256, []byte{0x30, 0x00})
+	assert.NotNil(t, err)
+}
@@ -4,7 +4,6 @@ import (
 	"crypto"
 	"crypto/rand"
 	"crypto/rsa"
-	"errors"
 
 	"github.com/jucardi/go-jwt/encoding"
 )
@@ -20,16 +19,20 @@ func (r *rsaSigner) Algorithm() string {
 
 func (r *rsaSigner) Sign(signingString string, privateKey interface{}) (string, error) {
 	// Validate type of key
-	key, ok := privateKey.(*rsa.PrivateKey)
+	k, opts := unwrapKey(privateKey)
+	key, ok := k.(*rsa.PrivateKey)
 	if !ok {
-		return "", errors.New("invalid key, expected *rsa.PrivateKey")
+		return "", invalidKey(r.alg, "invalid key, expected *rsa.PrivateKey")
+	}
+	if err := checkRSAKey(r.alg, &key.PublicKey, !opts.weak); err != nil {
+		return "", err
 	}
 
-	hasher := r.hash.New()
-	hasher.Write([]byte(signingString))
+	d := getDigest(r.hash)
+	defer putDigest(d)
 
 	// Sign the string and return the encoded bytes
-	if sigBytes, err := rsa.SignPKCS1v15(rand.Reader, key, r.hash, hasher.Sum(nil)); err == nil {
+	if sigBytes, err := rsa.SignPKCS1v15(rand.Reader, key, r.hash, d.hashSum([]byte(signingString))); err == nil {
 		return encoding.EncodeSegment(sigBytes), nil
 	} else {
 		return "", err
@@ -37,13 +40,16 @@ func (r *rsaSigner) Sign(signingString string, privateKey interface{}) (string,
 }
 
 func (r *rsaSigner) Verify(signed, signature []byte, publicKey interface{}) error {
-	key, ok := publicKey.(*rsa.PublicKey)
+	k, opts := unwrapKey(publicKey)
+	key, ok := k.(*rsa.PublicKey)
 	if !ok {
-		return errors.New("invalid key, expected *rsa.PublicKey")
+		return invalidKey(r.alg, "invalid key, expected *rsa.PublicKey")
+	}
+	if err := checkRSAKey(r.alg, key, !opts.weak); err != nil {
+		return err
 	}
 
-	hasher := r.hash.New()
-	hasher.Write(signed)
-
-	return rsa.VerifyPKCS1v15(key, r.hash, hasher.Sum(nil), signature)
+	d := getDigest(r.hash)
+	defer putDigest(d)
+	return rsa.VerifyPKCS1v15(key, r.hash, d.hashSum(signed), signature)
 }
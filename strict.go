@@ -0,0 +1,92 @@
+package jwt
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"unicode"
+	"unicode/utf8"
+)
+
+// StrictDecoding indicates whether `Parse` uses strict decoding by default when `WithStrictDecoding`
+// is not provided. It will be enabled by default in a future version.
+var StrictDecoding = false
+
+// verifyStrictJSON ensures the JSON document has no duplicate member names in any object and no
+// data after the top level value. Names differing only by case are duplicates, since
+// `encoding/json` matches struct fields case-insensitively.
+func verifyStrictJSON(name string, data []byte) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err := checkJSONValue(dec); err != nil {
+		return newErrorf(ErrStrictDecoding, "invalid token %s, %s", name, err.Error())
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return newErrorf(ErrStrictDecoding, "invalid token %s, unexpected data after the top level value", name)
+	}
+	return nil
+}
+
+// checkJSONValue consumes the next JSON value from the decoder, failing on duplicate member names
+func checkJSONValue(dec *json.Decoder) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	delim, ok := tok.(json.Delim)
+	if !ok {
+		return nil
+	}
+	switch delim {
+	case '{':
+		seen := map[string]bool{}
+		for dec.More() {
+			tok, err := dec.Token()
+			if err != nil {
+				return err
+			}
+			key, ok := tok.(string)
+			if !ok {
+				return fmt.Errorf("unexpected member name %v", tok)
+			}
+			folded := foldName(key)
+			if seen[folded] {
+				return fmt.Errorf("duplicate member '%s'", key)
+			}
+			seen[folded] = true
+			if err := checkJSONValue(dec); err != nil {
+				return err
+			}
+		}
+	case '[':
+		for dec.More() {
+			if err := checkJSONValue(dec); err != nil {
+				return err
+			}
+		}
+	}
+	// Consume the closing delimiter
+	_, err = dec.Token()
+	return err
+}
+
+// foldName folds the member name the same way `encoding/json` does to match struct fields, so
+// names matching the same field are detected as duplicates
+func foldName(name string) string {
+	out := make([]byte, 0, len(name))
+	for i := 0; i < len(name); {
+		if c := name[i]; c < utf8.RuneSelf {
+			if 'a' <= c && c <= 'z' {
+				c -= 'a' - 'A'
+			}
+			out = append(out, c)
+			i++
+			continue
+		}
+		r, n := utf8.DecodeRuneInString(name[i:])
+		out = utf8.AppendRune(out, unicode.ToUpper(unicode.ToLower(r)))
+		i += n
+	}
+	return string(out)
+}
@@ -0,0 +1,47 @@
+package jwt
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStrictDecodingDuplicates(t *testing.T) {
+	header := `{"alg":"HS256","typ":"JWT"}`
+	for _, claims := range []string{
+		`{"sub":"alice","sub":"mallory"}`,
+		`{"sub":"alice","Sub":"mallory"}`,
+		`{"sub":"alice","SUB":"mallory"}`,
+		`{"sub":"alice","ſub":"mallory"}`,
+		`{"fields":{"a":1,"A":2}}`,
+	} {
+		token := signRaw(t, header, claims)
+		_, err := ParseAndValidate(token, &ExtendedClaims{}, testSecret, WithStrictDecoding())
+		assert.True(t, ErrStrictDecoding.IsType(err), claims)
+
+		// Lenient decoding keeps the last member
+		_, err = ParseAndValidate(token, &ExtendedClaims{}, testSecret, WithStrictDecoding(false))
+		assert.Nil(t, err, claims)
+	}
+
+	token := signRaw(t, `{"alg":"HS256","ALG":"none"}`, `{"sub":"alice"}`)
+	_, err := ParseAndValidate(token, &ExtendedClaims{}, testSecret, WithStrictDecoding())
+	assert.True(t, ErrStrictDecoding.IsType(err))
+}
+
+func TestStrictDecoding(t *testing.T) {
+	header := `{"alg":"HS256","typ":"JWT"}`
+	claims := &ExtendedClaims{}
+	token := signRaw(t, header, `{"sub":"alice","scope":"read","fields":{"sub":"x"}}`)
+	_, err := ParseAndValidate(token, claims, testSecret, WithStrictDecoding())
+	assert.Nil(t, err)
+	assert.Equal(t, "alice", claims.Subject)
+
+	// Padded segments do not round-trip
+	_, err = ParseAndValidate(token+"=", &ExtendedClaims{}, testSecret, WithStrictDecoding())
+	assert.True(t, ErrStrictDecoding.IsType(err))
+
+	token = signRaw(t, header, `{"sub":"alice"} {}`)
+	_, err = ParseAndValidate(token, &ExtendedClaims{}, testSecret, WithStrictDecoding())
+	assert.True(t, ErrStrictDecoding.IsType(err))
+}
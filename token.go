@@ -1,7 +1,6 @@
 package jwt
 
 import (
-	"encoding/json"
 	"fmt"
 	"strings"
 
@@ -13,11 +12,15 @@ type TokenData struct {
 	Raw       string            // Contains the original token string
 	Algorithm signing.Algorithm // Indicates the signing algorithm used
 	Header    TokenHeader       // The JWT header, first segment of the token
-	Token     IToken            // The JWT claims, second segment of the token
+	Token     IToken            // The JWT claims, second segment of the token, decoded from RawClaims on demand
+	RawClaims []byte            // The JSON encoded claims, second segment of the token once base64 decoded
 	Signature []byte            // The JWT signature, third segment of the token
 
 	signed    []byte
 	validated bool
+	target    IToken
+	decoded   bool
+	codec     Codec
 }
 
 // ValidateSignature validates the signature of the parsed token with the provided public key and
@@ -34,7 +37,9 @@ func (c *TokenData) ValidateAll(publicKey interface{}) error {
 
 // ValidateSignature validates the signature of the parsed token with the provided public key
 //
-//   {publicKey} - The public key to use for the signature validation
+//   {publicKey} - The public key to use for the signature validation, or a KeyResolver to resolve
+//                 it from the token header. Unsecured tokens ("alg": "none") are rejected with
+//                 ErrUnsecuredToken unless signing.UnsafeAllowNone is provided.
 //
 func (c *TokenData) ValidateSignature(publicKey interface{}) error {
 	if c == nil {
@@ -46,41 +51,182 @@ func (c *TokenData) ValidateSignature(publicKey interface{}) error {
 	if c.validated {
 		return nil
 	}
+	publicKey, err := c.resolveKey(publicKey)
+	if err != nil {
+		return err
+	}
+	if c.Algorithm == signing.AlgorithmNone && publicKey != signing.UnsafeAllowNone {
+		return newError(ErrUnsecuredToken, "unsecured tokens are not accepted unless signing.UnsafeAllowNone is provided")
+	}
 	signer := c.Algorithm.Signer()
 	if signer == nil {
 		return newErrorf(ErrSigningAlgorithm, "algorithm '%s' not supported", c.Algorithm)
 	}
 	if err := signer.Verify(c.signed, c.Signature, publicKey); err != nil {
-		return err
+		return verificationError(err)
 	}
 	c.validated = true
 	return nil
 }
 
-// ValidateClaims returns the result of `IsValid` implementation of the token
+// resolveKey obtains the key from the provided KeyResolver, or returns the key as is
+func (c *TokenData) resolveKey(publicKey interface{}) (interface{}, error) {
+	return resolveKey(c.Header, publicKey, "validate the signature")
+}
+
+// resolveKey obtains the key from the provided KeyResolver using the header, or returns the key as
+// is. The purpose is only used in the error message.
+func resolveKey(header TokenHeader, key interface{}, purpose string) (interface{}, error) {
+	resolver, ok := key.(KeyResolver)
+	if !ok {
+		return key, nil
+	}
+	ret, err := resolver.ResolveKey(header)
+	if err != nil {
+		if _, ok := err.(*Error); ok {
+			return nil, err
+		}
+		return nil, newErrorf(ErrKeyResolution, "failed to resolve the key, %s", err.Error())
+	}
+	if ret == nil {
+		return nil, newErrorf(ErrKeyResolution, "no key was resolved to %s", purpose)
+	}
+	return ret, nil
+}
+
+// ValidateClaims returns the result of `IsValid` implementation of the token. If the claims were
+// not decoded yet, they are decoded into the target provided when parsing.
 func (c *TokenData) ValidateClaims() error {
-	if c == nil || c.Token == nil {
+	if c == nil {
+		return newErrorf(ErrNilToken, "failed to validate claims, token is nil")
+	}
+	if err := c.decodeClaims(); err != nil {
+		return err
+	}
+	if c.Token == nil {
 		return newErrorf(ErrNilToken, "failed to validate claims, token is nil")
 	}
 	return c.Token.IsValid()
 }
 
+// DecodeClaims decodes the raw claims into the provided target, which becomes the `Token` of this
+// instance. Decoding the claims of a token whose signature was not validated exposes unauthenticated
+// data to the target `UnmarshalJSON` implementations, validate the signature first when possible.
+//
+//   {target} - The instance where the token claims will be deserialized to.
+//
+func (c *TokenData) DecodeClaims(target IToken) error {
+	if c == nil {
+		return newErrorf(ErrNilToken, "failed to decode claims, token is nil")
+	}
+	c.target = target
+	c.decoded = false
+	return c.decodeClaims()
+}
+
+// decodeClaims decodes the raw claims into the target, only once
+func (c *TokenData) decodeClaims() error {
+	if c.decoded || c.RawClaims == nil {
+		return nil
+	}
+	if c.target == nil {
+		return newErrorf(ErrNilToken, "failed to unmarshal token body, target is nil")
+	}
+	if err := c.getCodec().Unmarshal(c.RawClaims, c.target); err != nil {
+		return newErrorf(ErrUnmarshalFailed, "failed to unmarshal token body, '%s'", err)
+	}
+	c.Token = c.target
+	c.decoded = true
+	return nil
+}
+
+// registeredClaims contains the registered claims used internally, read from the raw claims
+type registeredClaims struct {
+	Id        string
+	Issuer    string
+	Subject   string
+	ExpiresAt *NumericDate
+	IssuedAt  *NumericDate
+}
+
+// registered reads the "jti", "iss", "sub", "exp" and "iat" claims from the raw claims with the
+// codec of the token, without involving the target type. Returns an error if the claims are not an
+// object or a registered claim has an invalid value, so the checks relying on them fail closed.
+func (c *TokenData) registered() (registeredClaims, error) {
+	var ret registeredClaims
+	if c.RawClaims == nil {
+		return ret, nil
+	}
+	var claims map[string]interface{}
+	if err := c.getCodec().Unmarshal(c.RawClaims, &claims); err != nil {
+		return ret, fmt.Errorf("failed to read the registered claims, %s", err.Error())
+	}
+	var err error
+	if ret.Id, err = registeredString(claims, "jti"); err != nil {
+		return ret, err
+	}
+	if ret.Issuer, err = registeredString(claims, "iss"); err != nil {
+		return ret, err
+	}
+	if ret.Subject, err = registeredString(claims, "sub"); err != nil {
+		return ret, err
+	}
+	if ret.ExpiresAt, err = registeredDate(claims, "exp"); err != nil {
+		return ret, err
+	}
+	if ret.IssuedAt, err = registeredDate(claims, "iat"); err != nil {
+		return ret, err
+	}
+	return ret, nil
+}
+
+func registeredString(claims map[string]interface{}, name string) (string, error) {
+	switch v := claims[name].(type) {
+	case nil:
+		return "", nil
+	case string:
+		return v, nil
+	}
+	return "", fmt.Errorf("invalid '%s' claim, expected a string", name)
+}
+
+func registeredDate(claims map[string]interface{}, name string) (*NumericDate, error) {
+	ret, err := ParseNumericDate(claims[name], NumericDateParsing)
+	if err != nil {
+		return nil, fmt.Errorf("invalid '%s' claim, %s", name, err.Error())
+	}
+	return ret, nil
+}
+
+// getCodec returns the codec the token was parsed with, or the default one
+func (c *TokenData) getCodec() Codec {
+	if c.codec != nil {
+		return c.codec
+	}
+	return GetCodec()
+}
+
 // Sign attempts to obtain a signed JWT string token from the data contained within this instance.
 //
 //    {privateKey} - The private key to be used to sign the token
+//    {options}    - (optional) Options to customize the signed token, e.g. `WithThumbprintKeyID`
 //
-func (c *TokenData) Sign(privateKey interface{}) (string, error) {
+func (c *TokenData) Sign(privateKey interface{}, options ...SignOption) (string, error) {
 	if c == nil || c.Token == nil {
 		return "", newError(ErrNilToken, "failed to sign token, token is nil")
 	}
 	if privateKey == nil {
 		return "", newError(ErrInvalidKey, "privateKey is required")
 	}
+	opts := newSignOptions(options)
 	if c.Header == nil {
 		c.Header = TokenHeader{}
 	}
+	if opts.mediaType != "" {
+		c.Header.SetType(opts.mediaType)
+	}
 	if c.Header.Type() == "" {
-		c.Header.SetType(jwtType)
+		c.Header.SetType(MediaTypeJWT)
 	}
 	if c.Algorithm != "" {
 		c.Header.SetAlgorithm(c.Algorithm)
@@ -99,14 +245,22 @@ func (c *TokenData) Sign(privateKey interface{}) (string, error) {
 		return "", newErrorf(ErrSigningAlgorithm, "signer '%s' was not found", alg)
 	}
 
-	str, err := encode(c.Header, c.Token)
+	if opts.thumbprintKeyID {
+		kid, err := thumbprintKeyID(privateKey, opts.thumbprintHash)
+		if err != nil {
+			return "", err
+		}
+		c.Header.SetKeyID(kid)
+	}
+
+	str, err := encode(opts.codec, c.Header, c.Token)
 	if err != nil {
 		return "", err
 	}
 
 	signature, err := signer.Sign(str, privateKey)
 	if err != nil {
-		return "", err
+		return "", signingError(err)
 	}
 	return strings.Join([]string{str, signature}, "."), nil
 }
@@ -132,53 +286,104 @@ func Sign(token IToken, privateKey interface{}, algorithm ...signing.Algorithm)
 // Parse parses the provided JWT token. It does NOT validate the signature. For signature
 // validation use the returned *TokenData.ValidateSignature
 //
-// If both parsing and validating the signature are required in one step, use
-// `ParseAndValidate(token string, target IToken, publicKey interface{})` instead.
+// The claims are decoded into the target before the signature is validated. If both parsing and
+// validating the signature are required in one step, use `ParseAndValidate` instead, which only
+// decodes the claims once the signature is valid.
 //
 //   {tokenString} - The token string.
 //   {target}      - The instance where the token claims will be deserialized to.
+//   {options}     - (optional) Options to customize the parsing, e.g. `WithCriticalHeader`
+//
+func Parse(tokenString string, target IToken, options ...ParseOption) (*TokenData, error) {
+	ret, err := parse(tokenString, target, newParseOptions(options))
+	if err != nil {
+		return nil, err
+	}
+	if err := ret.decodeClaims(); err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+// ParseAndValidate parses the provided JWT token, validates its signature and the integrity of the clains.
+// The claims are only decoded into the target once the signature is valid.
 //
-func Parse(tokenString string, target IToken) (*TokenData, error) {
-	header, body, signature, signed, err := splitToken(tokenString)
+//   {tokenString} - The token string.
+//   {target}      - The instance where the token claims will be deserialized to.
+//   {publicKey}   - The public key to use for the signature validation, or a KeyResolver to resolve it
+//   {options}     - (optional) Options to customize the parsing, e.g. `WithVerifiedCache`
+//
+func ParseAndValidate(tokenString string, target IToken, publicKey interface{}, options ...ParseOption) (*TokenData, error) {
+	return parseAndValidate(tokenString, target, publicKey, newParseOptions(options))
+}
+
+// parseAndValidate parses the token and validates its signature, using the verified cache if set,
+// and its claims
+func parseAndValidate(tokenString string, target IToken, publicKey interface{}, opts *parseOptions) (*TokenData, error) {
+	ret, err := parse(tokenString, target, opts)
+	if err != nil {
+		return nil, err
+	}
+	if opts.cache != nil {
+		err = opts.cache.validateSignature(ret, publicKey)
+	} else {
+		err = ret.ValidateSignature(publicKey)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := ret.ValidateClaims(); err != nil {
+		return nil, err
+	}
+	if err := opts.checkState(ret); err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+// parse splits the token and decodes its header, keeping the claims as raw JSON
+func parse(tokenString string, target IToken, opts *parseOptions) (*TokenData, error) {
+	headerBuf := getBuffer()
+	defer putBuffer(headerBuf)
+	header, body, signature, signed, err := splitToken(tokenString, headerBuf, opts)
 	if err != nil {
 		return nil, err
 	}
+	if err := opts.limits.checkJSON("header", header, false); err != nil {
+		return nil, err
+	}
+	if err := opts.limits.checkJSON("claims", body, true); err != nil {
+		return nil, err
+	}
+	if opts.strict {
+		if err := verifyStrictJSON("header", header); err != nil {
+			return nil, err
+		}
+		if err := verifyStrictJSON("claims", body); err != nil {
+			return nil, err
+		}
+	}
 
 	h := TokenHeader{}
-	if err := json.Unmarshal(header, &h); err != nil {
+	if err := opts.codec.Unmarshal(header, &h); err != nil {
 		return nil, newErrorf(ErrUnmarshalFailed, "failed to unmarshal header, %s", err.Error())
 	}
-	if strings.ToLower(h.Type()) != "jwt" {
-		return nil, fmt.Errorf("unknown token type '%s', only JWT tokens are supported", h.Type())
+	if err := verifyType(h, opts.acceptedTypes); err != nil {
+		return nil, err
 	}
-
-	if err := json.Unmarshal(body, &target); err != nil {
-		return nil, newErrorf(ErrUnmarshalFailed, "failed to unmarshal token body, '%s'", err)
+	if err := verifyCritical(h, opts.critical); err != nil {
+		return nil, err
 	}
 
 	ret := &TokenData{
 		Raw:       tokenString,
 		Algorithm: h.Algorithm(),
 		Signature: signature,
-		Token:     target,
+		RawClaims: body,
 		Header:    h,
 		signed:    signed,
+		target:    target,
+		codec:     opts.codec,
 	}
 	return ret, nil
 }
-
-// ParseAndValidate parses the provided JWT token, validates its signature and the integrity of the clains.
-//
-//   {tokenString} - The token string.
-//   {target}      - The instance where the token claims will be deserialized to.
-//   {publicKey} - The public key to use for the signature validation
-//
-func ParseAndValidate(tokenString string, target IToken, publicKey interface{}) (*TokenData, error) {
-	if ret, err := Parse(tokenString, target); err != nil {
-		return nil, err
-	} else if err = ret.ValidateAll(publicKey); err != nil {
-		return nil, err
-	} else {
-		return ret, nil
-	}
-}
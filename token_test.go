@@ -0,0 +1,168 @@
+package jwt
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/json"
+	"testing"
+
+	"github.com/jucardi/go-jwt/keys"
+	"github.com/jucardi/go-jwt/signing"
+	"github.com/stretchr/testify/assert"
+)
+
+type testAlgorithm struct {
+	alg        signing.Algorithm
+	privateKey interface{}
+	publicKey  interface{}
+}
+
+func testAlgorithms(tb testing.TB) []testAlgorithm {
+	rsaKey, err := keys.LoadPrivateKey("test_assets/rsa.priv.pkcs1")
+	if err != nil {
+		tb.Fatal(err)
+	}
+	rsaPublic, _ := keys.PublicKey(rsaKey)
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	return []testAlgorithm{
+		{signing.AlgorithmHS256, testSecret, testSecret},
+		{signing.AlgorithmRS256, rsaKey, rsaPublic},
+		{signing.AlgorithmES256, ecKey, &ecKey.PublicKey},
+	}
+}
+
+func TestSignAndParseAndValidate(t *testing.T) {
+	for _, a := range testAlgorithms(t) {
+		token, err := Sign(testClaims(), a.privateKey, a.alg)
+		assert.Nil(t, err, a.alg.String())
+
+		claims := &ExtendedClaims{}
+		data, err := ParseAndValidate(token, claims, a.publicKey)
+		assert.Nil(t, err, a.alg.String())
+		assert.Equal(t, a.alg, data.Algorithm)
+		assert.Equal(t, "alice", claims.Subject)
+
+		// Tampering with any segment fails
+		tampered := token[:len(token)-4] + "AAAA"
+		_, err = ParseAndValidate(tampered, &ExtendedClaims{}, a.publicKey)
+		assert.True(t, ErrInvalidSignature.IsType(err), a.alg.String())
+	}
+}
+
+func TestWithThumbprintKeyID(t *testing.T) {
+	for _, a := range testAlgorithms(t)[1:] {
+		data := &TokenData{Token: testClaims()}
+		token, err := data.Sign(a.privateKey, WithThumbprintKeyID())
+		assert.Nil(t, err, a.alg.String())
+
+		expected, err := keys.Thumbprint(a.publicKey)
+		assert.Nil(t, err)
+		parsed, err := ParseAndValidate(token, &ExtendedClaims{}, a.publicKey)
+		assert.Nil(t, err, a.alg.String())
+		assert.Equal(t, expected, parsed.Header.KeyID(), a.alg.String())
+
+		// The key ID can be resolved from a JWK Set
+		jwk, err := keys.ExportJWK(a.publicKey)
+		assert.Nil(t, err)
+		jwk.KeyID = expected
+		set := &keys.JWKSet{Keys: []*keys.JWK{jwk}}
+		_, err = ParseAndValidate(token, &ExtendedClaims{}, KeyResolverFunc(func(h TokenHeader) (interface{}, error) {
+			return set.Find(h.KeyID()).Key()
+		}))
+		assert.Nil(t, err, a.alg.String())
+
+		expected, err = keys.Thumbprint(a.publicKey, crypto.SHA512)
+		assert.Nil(t, err)
+		token, err = (&TokenData{Token: testClaims()}).Sign(signing.WeakKey(a.privateKey), WithThumbprintKeyID(crypto.SHA512))
+		assert.Nil(t, err, a.alg.String())
+		parsed, err = Parse(token, &ExtendedClaims{})
+		assert.Nil(t, err)
+		assert.Equal(t, expected, parsed.Header.KeyID(), a.alg.String())
+	}
+
+	// The thumbprint of an HMAC secret would disclose a hash of the secret
+	_, err := (&TokenData{Token: testClaims()}).Sign(testSecret, WithThumbprintKeyID())
+	assert.True(t, ErrInvalidKey.IsType(err))
+}
+
+// recordingClaims records whether its claims were decoded
+type recordingClaims struct {
+	ExtendedClaims
+	decoded bool
+}
+
+func (r *recordingClaims) UnmarshalJSON(data []byte) error {
+	r.decoded = true
+	return json.Unmarshal(data, &r.ExtendedClaims)
+}
+
+func TestClaimsDecodedAfterSignature(t *testing.T) {
+	other := []byte("fedcba9876543210fedcba9876543210")
+
+	// The claims segment is valid base64url but not JSON, decoding it would fail
+	token := signRaw(t, `{"alg":"HS256","typ":"JWT"}`, `not json`)
+	_, err := ParseAndValidate(token, &ExtendedClaims{}, other)
+	assert.True(t, ErrInvalidSignature.IsType(err))
+	_, err = ParseAndValidate(token, &ExtendedClaims{}, other, WithVerifiedCache(NewVerifiedCache(10)))
+	assert.True(t, ErrInvalidSignature.IsType(err))
+	_, err = ParseAndValidate(token, &ExtendedClaims{}, testSecret)
+	assert.True(t, ErrUnmarshalFailed.IsType(err))
+
+	// The target never sees the claims of a token with an invalid signature
+	token, err = Sign(testClaims(), testSecret)
+	assert.Nil(t, err)
+	target := &recordingClaims{}
+	_, err = ParseAndValidate(token, target, other)
+	assert.True(t, ErrInvalidSignature.IsType(err))
+	assert.False(t, target.decoded)
+
+	data, err := ParseAndValidate(token, target, testSecret)
+	assert.Nil(t, err)
+	assert.True(t, target.decoded)
+	assert.Equal(t, "alice", target.Subject)
+	assert.True(t, json.Valid(data.RawClaims))
+}
+
+func BenchmarkParseAndValidate(b *testing.B) {
+	for _, a := range testAlgorithms(b) {
+		token, err := Sign(testClaims(), a.privateKey, a.alg)
+		if err != nil {
+			b.Fatal(err)
+		}
+		b.Run(a.alg.String(), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if _, err := ParseAndValidate(token, &ExtendedClaims{}, a.publicKey); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkVerify(b *testing.B) {
+	for _, a := range testAlgorithms(b) {
+		token, err := Sign(testClaims(), a.privateKey, a.alg)
+		if err != nil {
+			b.Fatal(err)
+		}
+		data, err := Parse(token, &ExtendedClaims{})
+		if err != nil {
+			b.Fatal(err)
+		}
+		signer := a.alg.Signer()
+		b.Run(a.alg.String(), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if err := signer.Verify(data.signed, data.Signature, a.publicKey); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -1,31 +1,65 @@
 package jwt
 
-import "github.com/jucardi/go-jwt/signing"
+import (
+	"github.com/jucardi/go-jwt/encryption"
+	"github.com/jucardi/go-jwt/keys"
+	"github.com/jucardi/go-jwt/signing"
+)
 
 const (
-	headerAlgKey  = "alg"
-	headerTypeKey = "typ"
+	headerAlgKey     = "alg"
+	headerTypeKey    = "typ"
+	headerKeyIDKey   = "kid"
+	headerCtyKey     = "cty"
+	headerX5CKey     = "x5c"
+	headerX5TKey     = "x5t"
+	headerX5TS256Key = "x5t#S256"
+	headerJKUKey     = "jku"
+	headerJWKKey     = "jwk"
+	headerX5UKey     = "x5u"
+	headerCritKey    = "crit"
+	headerEncKey     = "enc"
+	headerZipKey     = "zip"
 
-	jwtType = "JWT"
+	// MediaTypeJWT is the default "typ" header value
+	MediaTypeJWT = "JWT"
+	// MediaTypeAccessToken is the "typ" header value of OAuth 2.0 access tokens (RFC 9068)
+	MediaTypeAccessToken = "at+jwt"
+	// MediaTypeLogoutToken is the "typ" header value of OpenID Connect back-channel logout tokens
+	MediaTypeLogoutToken = "logout+jwt"
 )
 
 type IToken interface {
 	IsValid() error
 }
 
+// KeyResolver resolves the key to validate the signature of a token from its header. A KeyResolver
+// can be provided anywhere a public key is expected for signature validation.
+type KeyResolver interface {
+	ResolveKey(header TokenHeader) (interface{}, error)
+}
+
+// KeyResolverFunc allows a function to be used as a KeyResolver
+type KeyResolverFunc func(header TokenHeader) (interface{}, error)
+
+// ResolveKey invokes the function
+func (f KeyResolverFunc) ResolveKey(header TokenHeader) (interface{}, error) {
+	return f(header)
+}
+
 type IStandardClaims interface {
 	// Audience indicates the audience of this token
 	Audience() string
-	// ExpiresAt indicates the expiration of the token in UNIX time
-	ExpiresAt() int64
+	// ExpiresAt indicates the expiration of the token
+	ExpiresAt() *NumericDate
 	// Id is an optional unique id for the token
 	Id() string
-	// IssuedAt at indicates when the token was issued in UNIX time
-	IssuedAt() int64
+	// IssuedAt at indicates when the token was issued
+	IssuedAt() *NumericDate
 	// Issuer indicates who issued the token
 	Issuer() string
-	// NotBefore indicates when the token becomes valid in UNIX time
-	NotBefore() int64
+	// NotBefore indicates when the token becomes valid
+	NotBefore() *NumericDate
 	// Subject indicates the subject of the token
 	Subject() string
 }
@@ -38,17 +72,177 @@ func (h TokenHeader) Algorithm() signing.Algorithm {
 	return signing.Algorithm(getString(h, headerAlgKey))
 }
 
+// KeyAlgorithm retrieves the key management algorithm of an encrypted token, which is also
+// specified in the "alg" header
+func (h TokenHeader) KeyAlgorithm() encryption.KeyAlgorithm {
+	return encryption.KeyAlgorithm(getString(h, headerAlgKey))
+}
+
+// ContentEncryption retrieves the content encryption algorithm (enc) of an encrypted token
+func (h TokenHeader) ContentEncryption() encryption.ContentEncryption {
+	return encryption.ContentEncryption(getString(h, headerEncKey))
+}
+
 // Type indicates the token type (JWT)
 func (h TokenHeader) Type() string {
 	return getString(h, headerTypeKey)
 }
 
+// KeyID retrieves the key ID (kid) specified in the header
+func (h TokenHeader) KeyID() string {
+	return getString(h, headerKeyIDKey)
+}
+
+// ContentType retrieves the content type (cty) of the token payload, "JWT" for nested tokens
+func (h TokenHeader) ContentType() string {
+	return getString(h, headerCtyKey)
+}
+
+// JWKSetURL retrieves the URL of the JWK Set containing the signing key (jku)
+func (h TokenHeader) JWKSetURL() string {
+	return getString(h, headerJKUKey)
+}
+
+// JWK retrieves the public key embedded in the header (jwk). Returns nil if not set.
+//
+//   {codec} - (optional) The codec the header was parsed with, the default one if not provided
+//
+func (h TokenHeader) JWK(codec ...Codec) (*keys.JWK, error) {
+	val, ok := h[headerJWKKey]
+	if !ok || val == nil {
+		return nil, nil
+	}
+	if ret, ok := val.(*keys.JWK); ok {
+		return ret, nil
+	}
+	c := GetCodec()
+	if len(codec) > 0 && codec[0] != nil {
+		c = codec[0]
+	}
+	data, err := c.Marshal(val)
+	if err != nil {
+		return nil, newErrorf(ErrUnmarshalFailed, "invalid '%s' header, %s", headerJWKKey, err.Error())
+	}
+	ret := &keys.JWK{}
+	if err := c.Unmarshal(data, ret); err != nil {
+		return nil, newErrorf(ErrUnmarshalFailed, "invalid '%s' header, %s", headerJWKKey, err.Error())
+	}
+	return ret, nil
+}
+
+// CertificateURL retrieves the URL of the X.509 certificate chain of the signing key (x5u)
+func (h TokenHeader) CertificateURL() string {
+	return getString(h, headerX5UKey)
+}
+
+// CertificateThumbprint retrieves the base64url encoded SHA-1 thumbprint of the certificate of the
+// signing key (x5t)
+func (h TokenHeader) CertificateThumbprint() string {
+	return getString(h, headerX5TKey)
+}
+
+// Critical retrieves the list of header extensions that must be understood to process the token
+// (crit). Returns nil if not set or if the value is not an array of strings.
+func (h TokenHeader) Critical() []string {
+	switch v := getVal(h, headerCritKey).(type) {
+	case []string:
+		return v
+	case []interface{}:
+		ret := make([]string, 0, len(v))
+		for _, item := range v {
+			str, ok := item.(string)
+			if !ok {
+				return nil
+			}
+			ret = append(ret, str)
+		}
+		return ret
+	}
+	return nil
+}
+
+// Get retrieves the value of a custom header parameter
+//
+//   {name} - The header parameter name
+//
+func (h TokenHeader) Get(name string) interface{} {
+	return getVal(h, name)
+}
+
 // SetAlgorithm sets the algorithm to be used in the token
 func (h TokenHeader) SetAlgorithm(alg signing.Algorithm) {
 	h[headerAlgKey] = alg.String()
 }
 
+// SetKeyAlgorithm sets the key management algorithm of an encrypted token, stored in the "alg" header
+func (h TokenHeader) SetKeyAlgorithm(alg encryption.KeyAlgorithm) {
+	h[headerAlgKey] = alg.String()
+}
+
+// SetContentEncryption sets the content encryption algorithm (enc) of an encrypted token
+func (h TokenHeader) SetContentEncryption(enc encryption.ContentEncryption) {
+	h[headerEncKey] = enc.String()
+}
+
 // SetType sets the token type value
 func (h TokenHeader) SetType(t string) {
 	h[headerTypeKey] = t
 }
+
+// SetKeyID sets the key ID (kid) value
+func (h TokenHeader) SetKeyID(kid string) {
+	h[headerKeyIDKey] = kid
+}
+
+// SetContentType sets the content type (cty) value
+func (h TokenHeader) SetContentType(cty string) {
+	h[headerCtyKey] = cty
+}
+
+// SetJWKSetURL sets the JWK Set URL (jku) value
+func (h TokenHeader) SetJWKSetURL(url string) {
+	h[headerJKUKey] = url
+}
+
+// SetJWK embeds the provided public key in the header (jwk). Private key members are removed.
+func (h TokenHeader) SetJWK(jwk *keys.JWK) error {
+	if jwk == nil {
+		delete(h, headerJWKKey)
+		return nil
+	}
+	pub, err := jwk.Public()
+	if err != nil {
+		return newErrorf(ErrInvalidKey, "unable to set the '%s' header, %s", headerJWKKey, err.Error())
+	}
+	h[headerJWKKey] = pub
+	return nil
+}
+
+// SetCertificateURL sets the X.509 certificate chain URL (x5u) value
+func (h TokenHeader) SetCertificateURL(url string) {
+	h[headerX5UKey] = url
+}
+
+// SetCertificateThumbprint sets the SHA-1 certificate thumbprint (x5t) value
+func (h TokenHeader) SetCertificateThumbprint(thumbprint string) {
+	h[headerX5TKey] = thumbprint
+}
+
+// SetCritical sets the list of header extensions that must be understood to process the token
+// (crit). The extensions should also be set in the header.
+func (h TokenHeader) SetCritical(names ...string) {
+	if len(names) == 0 {
+		delete(h, headerCritKey)
+		return
+	}
+	h[headerCritKey] = names
+}
+
+// Set sets the value of a custom header parameter
+//
+//   {name}  - The header parameter name
+//   {value} - The header parameter value
+//
+func (h TokenHeader) Set(name string, value interface{}) {
+	h[name] = value
+}
@@ -1,46 +1,85 @@
 package jwt
 
 import (
-	"encoding/json"
+	"crypto"
+	"encoding/base64"
 	"errors"
-	"fmt"
 	"strings"
-	"time"
 
 	"github.com/jucardi/go-jwt/encoding"
+	"github.com/jucardi/go-jwt/encryption"
+	"github.com/jucardi/go-jwt/keys"
+	"github.com/jucardi/go-jwt/signing"
 )
 
-func splitToken(token string) (header, body, signature, signed []byte, err error) {
-	pieces := strings.Split(token, ".")
+// splitToken splits the token and decodes its segments without re-joining the signing input. The
+// signing input, claims and signature share a single allocation, while the header is decoded into
+// the provided pooled buffer, so it is only valid until the buffer is released.
+func splitToken(token string, headerBuf *[]byte, opts *parseOptions) (header, body, signature, signed []byte, err error) {
+	limits := opts.limits
+	if err = limits.checkTokenLength(token); err != nil {
+		return
+	}
+	first := strings.IndexByte(token, '.')
+	last := strings.LastIndexByte(token, '.')
+	if first < 0 || first == last || strings.IndexByte(token[first+1:last], '.') >= 0 {
+		err = newError(ErrMalformedToken, "unexpected number of pieces")
+		return
+	}
 
-	if len(pieces) != 3 {
-		err = errors.New("unexpected number of pieces")
+	if err = checkSegmentSize("header", token[:first], limits.MaxHeaderSize); err != nil {
 		return
 	}
+	if err = checkSegmentSize("claims", token[first+1:last], limits.MaxClaimsSize); err != nil {
+		return
+	}
+
+	decode := encoding.AppendDecodeSegment
+	if opts.strict {
+		decode = encoding.AppendDecodeSegmentStrict
+	}
 
-	if header, err = encoding.DecodeSegment(pieces[0]); err != nil {
-		err = fmt.Errorf("failed to decode header, %s", err.Error())
+	// The signing input is the token up to the last dot, the decoded claims and signature are
+	// appended after it in the same buffer
+	size := len(token) + base64.RawURLEncoding.DecodedLen(last-first-1) + base64.RawURLEncoding.DecodedLen(len(token)-last-1)
+	buf := append(make([]byte, 0, size), token...)
+	raw := buf[:len(token):len(token)]
+	signed = raw[:last:last]
+
+	if header, err = decode((*headerBuf)[:0], raw[:first]); err != nil {
+		header, err = nil, decodeError("header", err, opts.strict)
 		return
 	}
-	if body, err = encoding.DecodeSegment(pieces[1]); err != nil {
-		err = fmt.Errorf("failed to decode body, %s", err.Error())
+	// Keep the grown buffer, so it is reused once returned to the pool
+	*headerBuf = header
+	rest := buf[len(token):cap(buf)]
+	if body, err = decode(rest[:0], raw[first+1:last]); err != nil {
+		header, err = nil, decodeError("body", err, opts.strict)
 		return
 	}
-	if signature, err = encoding.DecodeSegment(pieces[2]); err != nil {
-		err = fmt.Errorf("failed to decode signature, %s", err.Error())
+	body = body[:len(body):len(body)]
+	rest = rest[len(body):]
+	if signature, err = decode(rest[:0], raw[last+1:]); err != nil {
+		header, err = nil, decodeError("signature", err, opts.strict)
 		return
 	}
-
-	signed = []byte(strings.Join(pieces[:len(pieces)-1], "."))
+	signature = signature[:len(signature):len(signature)]
 	return
 }
 
-func encode(header TokenHeader, token IToken) (string, error) {
-	hBytes, err := json.Marshal(header)
+func decodeError(segment string, err error, strict bool) error {
+	if strict {
+		return newErrorf(ErrStrictDecoding, "failed to decode %s, %s", segment, err.Error())
+	}
+	return newErrorf(ErrMalformedToken, "failed to decode %s, %s", segment, err.Error())
+}
+
+func encode(codec Codec, header TokenHeader, token IToken) (string, error) {
+	hBytes, err := codec.Marshal(header)
 	if err != nil {
 		return "", errors.New("failed to marshal token header, " + err.Error())
 	}
-	tBytes, err := json.Marshal(token)
+	tBytes, err := codec.Marshal(token)
 	if err != nil {
 		return "", errors.New("failed to marshal token body, " + err.Error())
 	}
@@ -48,6 +87,44 @@ func encode(header TokenHeader, token IToken) (string, error) {
 	return strings.Join([]string{encoding.EncodeSegment(hBytes), encoding.EncodeSegment(tBytes)}, "."), nil
 }
 
+// verificationError converts the errors returned when verifying a signature into typed errors
+func verificationError(err error) error {
+	if errors.Is(err, signing.ErrInvalidKey) {
+		return newError(ErrInvalidKey, err.Error())
+	}
+	return newError(ErrInvalidSignature, err.Error())
+}
+
+// signingError converts the key validation errors returned by the signers into ErrInvalidKey errors
+func signingError(err error) error {
+	if errors.Is(err, signing.ErrInvalidKey) {
+		return newError(ErrInvalidKey, err.Error())
+	}
+	return err
+}
+
+// encryptionError converts the key validation errors returned by the key managers and content
+// encryptors into ErrInvalidKey errors
+func encryptionError(err error) error {
+	if errors.Is(err, encryption.ErrInvalidKey) {
+		return newError(ErrInvalidKey, err.Error())
+	}
+	return err
+}
+
+// thumbprintKeyID computes the RFC 7638 thumbprint of the public half of the provided private key
+func thumbprintKeyID(privateKey interface{}, hash crypto.Hash) (string, error) {
+	pub, err := keys.PublicKey(signing.UnwrapKey(privateKey))
+	if err != nil {
+		return "", newErrorf(ErrInvalidKey, "unable to derive the key ID, %s", err.Error())
+	}
+	kid, err := keys.Thumbprint(pub, hash)
+	if err != nil {
+		return "", newErrorf(ErrInvalidKey, "unable to derive the key ID, %s", err.Error())
+	}
+	return kid, nil
+}
+
 func getVal(m map[string]interface{}, key string) interface{} {
 	if val, ok := m[key]; ok {
 		return val
@@ -62,22 +139,12 @@ func getString(m map[string]interface{}, key string) string {
 	return ""
 }
 
-func getTimeInt(m map[string]interface{}, key string) int64 {
-	val, ok := m[key]
-	if !ok {
-		return 0
-	}
-	switch v := val.(type) {
-	case int64:
-		return v
-	case float64:
-		return int64(v)
-	case json.Number:
-		ret, _ := v.Int64()
-		return ret
-	case string:
-		date, _ := time.Parse(time.RFC3339, v)
-		return date.UTC().Unix()
-	}
-	return 0
+// getNumericDate parses the NumericDate in the provided key according to `NumericDateParsing`.
+// Values that cannot be parsed are treated as absent.
+func getNumericDate(m map[string]interface{}, key string) *NumericDate {
+	ret, err := ParseNumericDate(m[key], NumericDateParsing)
+	if err != nil {
+		return nil
+	}
+	return ret
 }
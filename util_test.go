@@ -0,0 +1,33 @@
+package jwt
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jucardi/go-jwt/encoding"
+	"github.com/jucardi/go-jwt/signing"
+	"github.com/stretchr/testify/assert"
+)
+
+var testSecret = []byte("0123456789abcdef0123456789abcdef")
+
+func testClaims() *ExtendedClaims {
+	now := time.Now()
+	return &ExtendedClaims{
+		Issuer:    "issuer",
+		Subject:   "alice",
+		Audience:  "audience",
+		Id:        "id",
+		IssuedAt:  NewNumericDate(now),
+		ExpiresAt: NewNumericDate(now.Add(time.Hour)),
+		Scope:     "read write",
+	}
+}
+
+// signRaw signs the provided raw JSON header and claims with the test secret
+func signRaw(t *testing.T, header, claims string) string {
+	str := encoding.EncodeSegment([]byte(header)) + "." + encoding.EncodeSegment([]byte(claims))
+	signature, err := signing.AlgorithmHS256.Signer().Sign(str, testSecret)
+	assert.Nil(t, err)
+	return str + "." + signature
+}
@@ -2,23 +2,23 @@ package jwt
 
 import "time"
 
-func verifyExpiresAt(now, exp int64) error {
-	if exp != 0 && exp < now {
-		return newErrorf(ErrTokenExpired, "token expired %s ago", time.Duration(now-exp))
+func verifyExpiresAt(now time.Time, exp *NumericDate) error {
+	if exp != nil && exp.Before(now) {
+		return newErrorf(ErrTokenExpired, "token expired %s ago", now.Sub(exp.Time))
 	}
 	return nil
 }
 
-func verifyIssuedAt(now, iat int64) error {
-	if iat != 0 && iat > now {
-		return newErrorf(ErrBeforeIssued, "token used %s before issued", time.Duration(iat-now))
+func verifyIssuedAt(now time.Time, iat *NumericDate) error {
+	if iat != nil && iat.After(now) {
+		return newErrorf(ErrBeforeIssued, "token used %s before issued", iat.Sub(now))
 	}
 	return nil
 }
 
-func verifyNotBefore(now, nbf int64) error {
-	if nbf != 0 && nbf > now {
-		return newErrorf(ErrNotBefore, "token not valid for the next %s", time.Duration(now-nbf))
+func verifyNotBefore(now time.Time, nbf *NumericDate) error {
+	if nbf != nil && nbf.After(now) {
+		return newErrorf(ErrNotBefore, "token not valid for the next %s", nbf.Sub(now))
 	}
 	return nil
 }
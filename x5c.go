@@ -0,0 +1,212 @@
+package jwt
+
+import (
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"time"
+
+	"github.com/jucardi/go-jwt/encoding"
+)
+
+// CertificateOptions defines how the X.509 certificate chain in the "x5c" header is verified
+type CertificateOptions struct {
+	// Roots is the pool of trusted root certificates. Required, the system roots are never used.
+	Roots *x509.CertPool
+	// Intermediates is an optional pool of intermediate certificates, in addition to the ones
+	// included in the "x5c" header
+	Intermediates *x509.CertPool
+	// KeyUsages is the list of extended key usages the leaf certificate must be valid for. If
+	// empty, any extended key usage is accepted.
+	KeyUsages []x509.ExtKeyUsage
+	// Time is the time at which the chain must be valid, the current time if zero. The claims of
+	// the token, such as "iat", are never used since they are asserted by the signer and could be
+	// backdated into the validity period of an expired certificate.
+	Time time.Time
+	// Subject, if set, must match the common name of the leaf certificate subject
+	Subject string
+	// DNSName, if set, must match one of the DNS subject alternative names of the leaf certificate
+	DNSName string
+	// VerifyLeaf is an optional additional check on the leaf certificate once the chain is verified
+	VerifyLeaf func(leaf *x509.Certificate) error
+}
+
+// CertificateChain parses the X.509 certificate chain in the "x5c" header. The first certificate
+// is the one containing the key used to sign the token. Returns nil if the header is not set.
+func (h TokenHeader) CertificateChain() ([]*x509.Certificate, error) {
+	val, ok := h[headerX5CKey]
+	if !ok {
+		return nil, nil
+	}
+	values, ok := val.([]interface{})
+	if !ok {
+		if s, isStrings := val.([]string); isStrings {
+			for _, v := range s {
+				values = append(values, v)
+			}
+		} else {
+			return nil, newErrorf(ErrInvalidCertificate, "invalid '%s' header, expected an array of strings", headerX5CKey)
+		}
+	}
+
+	ret := make([]*x509.Certificate, 0, len(values))
+	for i, v := range values {
+		str, ok := v.(string)
+		if !ok {
+			return nil, newErrorf(ErrInvalidCertificate, "invalid '%s' header, expected an array of strings", headerX5CKey)
+		}
+		// As per RFC 7515, the certificates are standard base64 encoded (not base64url)
+		der, err := base64.StdEncoding.DecodeString(str)
+		if err != nil {
+			return nil, newErrorf(ErrInvalidCertificate, "failed to decode certificate %d in '%s' header, %s", i, headerX5CKey, err.Error())
+		}
+		cert, err := x509.ParseCertificate(der)
+		if err != nil {
+			return nil, newErrorf(ErrInvalidCertificate, "failed to parse certificate %d in '%s' header, %s", i, headerX5CKey, err.Error())
+		}
+		ret = append(ret, cert)
+	}
+	return ret, nil
+}
+
+// SetCertificateChain sets the "x5c" header to the provided chain and the "x5t#S256" header to the
+// SHA-256 thumbprint of the leaf certificate. The leaf certificate must be the first one.
+//
+//   {chain} - The certificate chain, starting with the certificate of the signing key
+//
+func (h TokenHeader) SetCertificateChain(chain []*x509.Certificate) {
+	values := make([]string, 0, len(chain))
+	for _, cert := range chain {
+		values = append(values, base64.StdEncoding.EncodeToString(cert.Raw))
+	}
+	h[headerX5CKey] = values
+	if len(chain) > 0 {
+		h.SetCertificateThumbprintS256(certificateThumbprint(chain[0]))
+	}
+}
+
+// CertificateThumbprintS256 retrieves the "x5t#S256" header, the base64url encoded SHA-256
+// thumbprint of the DER encoding of the certificate of the signing key
+func (h TokenHeader) CertificateThumbprintS256() string {
+	return getString(h, headerX5TS256Key)
+}
+
+// SetCertificateThumbprintS256 sets the "x5t#S256" header value
+func (h TokenHeader) SetCertificateThumbprintS256(thumbprint string) {
+	h[headerX5TS256Key] = thumbprint
+}
+
+// VerifyCertificateChain verifies the certificate chain in the "x5c" header against the trusted
+// roots in the provided options and returns the leaf certificate. It does NOT validate the token
+// signature, use `ValidateSignatureWithChain` to do both.
+//
+//   {opts} - The certificate verification options
+//
+func (c *TokenData) VerifyCertificateChain(opts *CertificateOptions) (*x509.Certificate, error) {
+	if c == nil {
+		return nil, newError(ErrNilToken, "failed to verify certificate chain, token is nil")
+	}
+	if opts == nil || opts.Roots == nil {
+		return nil, newError(ErrInvalidCertificate, "a root certificate pool is required to verify the certificate chain")
+	}
+
+	chain, err := c.Header.CertificateChain()
+	if err != nil {
+		return nil, err
+	}
+	if len(chain) == 0 {
+		return nil, newErrorf(ErrInvalidCertificate, "the token has no '%s' header", headerX5CKey)
+	}
+	leaf := chain[0]
+
+	if thumbprint := c.Header.CertificateThumbprintS256(); thumbprint != "" && thumbprint != certificateThumbprint(leaf) {
+		return nil, newErrorf(ErrInvalidCertificate, "the '%s' header does not match the leaf certificate", headerX5TS256Key)
+	}
+
+	intermediates := x509.NewCertPool()
+	if opts.Intermediates != nil {
+		intermediates = opts.Intermediates.Clone()
+	}
+	for _, cert := range chain[1:] {
+		intermediates.AddCert(cert)
+	}
+
+	verifyTime := opts.Time
+	if verifyTime.IsZero() {
+		verifyTime = time.Now()
+	}
+
+	keyUsages := opts.KeyUsages
+	if len(keyUsages) == 0 {
+		keyUsages = []x509.ExtKeyUsage{x509.ExtKeyUsageAny}
+	}
+
+	if _, err := leaf.Verify(x509.VerifyOptions{
+		Roots:         opts.Roots,
+		Intermediates: intermediates,
+		CurrentTime:   verifyTime,
+		KeyUsages:     keyUsages,
+		DNSName:       opts.DNSName,
+	}); err != nil {
+		return nil, newErrorf(ErrInvalidCertificate, "failed to verify certificate chain, %s", err.Error())
+	}
+
+	if leaf.KeyUsage != 0 && leaf.KeyUsage&x509.KeyUsageDigitalSignature == 0 {
+		return nil, newError(ErrInvalidCertificate, "the leaf certificate is not valid for digital signatures")
+	}
+	if opts.Subject != "" && opts.Subject != leaf.Subject.CommonName {
+		return nil, newErrorf(ErrInvalidCertificate, "wrong certificate subject %s", leaf.Subject.CommonName)
+	}
+	if opts.VerifyLeaf != nil {
+		if err := opts.VerifyLeaf(leaf); err != nil {
+			return nil, newErrorf(ErrInvalidCertificate, "leaf certificate verification failed, %s", err.Error())
+		}
+	}
+	return leaf, nil
+}
+
+// ValidateSignatureWithChain verifies the certificate chain in the "x5c" header and validates the
+// signature of the token with the public key of the leaf certificate
+//
+//   {opts} - The certificate verification options
+//
+func (c *TokenData) ValidateSignatureWithChain(opts *CertificateOptions) error {
+	leaf, err := c.VerifyCertificateChain(opts)
+	if err != nil {
+		return err
+	}
+	return c.ValidateSignature(leaf.PublicKey)
+}
+
+// ParseAndValidateWithChain parses the provided JWT token, verifies the certificate chain in its
+// "x5c" header, validates its signature with the leaf certificate public key and the integrity of
+// the claims.
+//
+//   {tokenString} - The token string.
+//   {target}      - The instance where the token claims will be deserialized to.
+//   {opts}        - The certificate verification options
+//   {options}     - (optional) Options to customize the parsing
+//
+func ParseAndValidateWithChain(tokenString string, target IToken, opts *CertificateOptions, options ...ParseOption) (*TokenData, error) {
+	parseOpts := newParseOptions(options)
+	ret, err := parse(tokenString, target, parseOpts)
+	if err != nil {
+		return nil, err
+	}
+	if err := ret.ValidateSignatureWithChain(opts); err != nil {
+		return nil, err
+	}
+	if err := ret.ValidateClaims(); err != nil {
+		return nil, err
+	}
+	if err := parseOpts.checkState(ret); err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+func certificateThumbprint(cert *x509.Certificate) string {
+	sum := sha256.Sum256(cert.Raw)
+	return encoding.EncodeSegment(sum[:])
+}
+
@@ -0,0 +1,97 @@
+package jwt
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// newTestCertificate creates a certificate valid between the provided times, signed by the parent
+// or self-signed if the parent is nil
+func newTestCertificate(t *testing.T, name string, notBefore, notAfter time.Time, parent *x509.Certificate, parentKey *ecdsa.PrivateKey) (*x509.Certificate, *ecdsa.PrivateKey) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: name},
+		NotBefore:             notBefore,
+		NotAfter:              notAfter,
+		KeyUsage:              x509.KeyUsageDigitalSignature,
+		BasicConstraintsValid: true,
+	}
+	if parent == nil {
+		template.IsCA = true
+		template.KeyUsage |= x509.KeyUsageCertSign
+		parent, parentKey = template, key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, &key.PublicKey, parentKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cert, key
+}
+
+func signWithChain(t *testing.T, claims *ExtendedClaims, key *ecdsa.PrivateKey, chain ...*x509.Certificate) string {
+	data := &TokenData{Header: TokenHeader{}, Token: claims}
+	data.Header.SetCertificateChain(chain)
+	token, err := data.Sign(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return token
+}
+
+func TestVerifyCertificateChain(t *testing.T) {
+	now := time.Now()
+	ca, caKey := newTestCertificate(t, "root", now.Add(-time.Hour), now.Add(time.Hour), nil, nil)
+	leaf, leafKey := newTestCertificate(t, "signer", now.Add(-time.Hour), now.Add(time.Hour), ca, caKey)
+	roots := x509.NewCertPool()
+	roots.AddCert(ca)
+
+	token := signWithChain(t, &ExtendedClaims{ExpiresAt: NewNumericDate(now.Add(time.Minute))}, leafKey, leaf)
+	data, err := ParseAndValidateWithChain(token, &ExtendedClaims{}, &CertificateOptions{Roots: roots, Subject: "signer"})
+	assert.Nil(t, err)
+	assert.NotNil(t, data)
+
+	_, err = ParseAndValidateWithChain(token, &ExtendedClaims{}, &CertificateOptions{Roots: roots, Subject: "other"})
+	assert.True(t, ErrInvalidCertificate.IsType(err), "%v", err)
+
+	_, err = ParseAndValidateWithChain(token, &ExtendedClaims{}, &CertificateOptions{Roots: x509.NewCertPool()})
+	assert.True(t, ErrInvalidCertificate.IsType(err), "%v", err)
+}
+
+func TestVerifyCertificateChainBackdatedToken(t *testing.T) {
+	now := time.Now()
+	ca, caKey := newTestCertificate(t, "root", now.Add(-72*time.Hour), now.Add(time.Hour), nil, nil)
+	expired, expiredKey := newTestCertificate(t, "signer", now.Add(-48*time.Hour), now.Add(-24*time.Hour), ca, caKey)
+	roots := x509.NewCertPool()
+	roots.AddCert(ca)
+
+	// The key of the expired certificate signs a token with "iat" within the certificate validity
+	token := signWithChain(t, &ExtendedClaims{
+		IssuedAt:  NewNumericDate(now.Add(-36 * time.Hour)),
+		ExpiresAt: NewNumericDate(now.Add(time.Hour)),
+	}, expiredKey, expired)
+
+	_, err := ParseAndValidateWithChain(token, &ExtendedClaims{}, &CertificateOptions{Roots: roots})
+	assert.True(t, ErrInvalidCertificate.IsType(err), "%v", err)
+
+	// Validation at an explicit time is still supported
+	data, err := Parse(token, &ExtendedClaims{})
+	assert.Nil(t, err)
+	_, err = data.VerifyCertificateChain(&CertificateOptions{Roots: roots, Time: now.Add(-36 * time.Hour)})
+	assert.Nil(t, err)
+}